@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jimmyfrasche/invert"
+)
+
+func Columns(header []string, RS, W string, Stdin io.Reader) (ret interface{}, err error) {
+	rs, err := cmpl(RS)
+	if err != nil {
+		return
+	}
+
+	header, types, err := headerTypes(header)
+	if err != nil {
+		return
+	}
+
+	var cols [][]int
+	if W != "" {
+		if cols, err = widths(W); err != nil {
+			return
+		}
+	}
+
+	stdin, err := ioutil.ReadAll(Stdin)
+	if err != nil {
+		return
+	}
+
+	pairs := rs.FindAllIndex(stdin, -1)
+	records := invert.Indicies(pairs, len(stdin))
+	lines := make([][]rune, 0, len(records))
+	for _, p := range records {
+		lines = append(lines, []rune(string(stdin[p[0]:p[1]])))
+	}
+
+	//without explicit columns, the header line is required to find them
+	first := isFirstLine(header) || cols == nil
+	if isFirstLine(header) {
+		header = nil
+	}
+	var hline []rune
+	if first && len(lines) > 0 {
+		hline, lines, records = lines[0], lines[1:], records[1:]
+	}
+	if cols == nil {
+		cols = aligned(hline, lines)
+	}
+	if header == nil && hline != nil {
+		header = cut(cols, hline)
+		seen := map[string]bool{}
+		for _, h := range header {
+			if seen[h] {
+				return nil, fmt.Errorf("duplicate column %q in header line %q", h, string(hline))
+			}
+			seen[h] = true
+		}
+	}
+
+	names := hdr2map(header, false)
+	if len(names) > 0 || first {
+		out := newNamer(names, types)
+		nrs := recordNumbers(pairs, records)
+		for i, line := range lines {
+			if err = out.add(nrs[i]-1, cut(cols, line)); err != nil {
+				return nil, err
+			}
+		}
+		ret = out.rows()
+	} else {
+		out := make([]*record, 0, len(lines))
+		name := filename(Stdin)
+		nrs := recordNumbers(pairs, records)
+		for i, line := range lines {
+			p := records[i]
+			out = append(out, &record{
+				Fields:   cut(cols, line),
+				Line:     string(line),
+				NR:       nrs[i],
+				FILENAME: name,
+				Start:    p[0],
+				End:      p[1],
+			})
+		}
+		ret = out
+	}
+
+	return
+}
+
+func widths(W string) (cols [][]int, err error) {
+	//W is a list of 1-based, inclusive ranges like 1-8,9-20,21-
+	//but cols are 0-based, half-open, and an end of -1 is the end of the line.
+	for _, w := range strings.Split(W, ",") {
+		w = strings.TrimSpace(w)
+		start, end := w, w
+		if i := strings.Index(w, "-"); i >= 0 {
+			start, end = w[:i], w[i+1:]
+		}
+
+		s, err := strconv.Atoi(start)
+		if err != nil || s < 1 {
+			return nil, fmt.Errorf("invalid column range %q", w)
+		}
+		e := -1
+		if end != "" {
+			if e, err = strconv.Atoi(end); err != nil || e < s {
+				return nil, fmt.Errorf("invalid column range %q", w)
+			}
+		}
+		cols = append(cols, []int{s - 1, e})
+	}
+	return
+}
+
+func aligned(header []rune, lines [][]rune) (cols [][]int) {
+	//A column ends at the last position before the next name in the header
+	//that is blank in every line, so right-aligned values wider than their
+	//name are kept whole.
+	//If there is no such position both names belong to the same column,
+	//as with the header "CONTAINER ID" in the output of docker ps.
+	//Names separated by a single space are also the same column unless
+	//there are values under the second, so that a multi-word name stays
+	//whole when its values are shorter than its first word.
+	blank := func(p int) bool {
+		for _, line := range lines {
+			if p < len(line) && !unicode.IsSpace(line[p]) {
+				return false
+			}
+		}
+		return true
+	}
+	empty := func(p int) bool {
+		//nothing under the name beginning at p
+		for ; p < len(header) && !unicode.IsSpace(header[p]); p++ {
+			if !blank(p) {
+				return false
+			}
+		}
+		return true
+	}
+
+	start := 0
+	named := false
+	for p, r := range header {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		single := p > 1 && !unicode.IsSpace(header[p-2])
+		if named && unicode.IsSpace(header[p-1]) && !(single && empty(p)) {
+			for q := p - 1; q > start && unicode.IsSpace(header[q]); q-- {
+				if blank(q) {
+					cols = append(cols, []int{start, q})
+					start = q
+					break
+				}
+			}
+		}
+		named = true
+	}
+	return append(cols, []int{start, -1})
+}
+
+func cut(cols [][]int, line []rune) []string {
+	out := make([]string, 0, len(cols))
+	for _, c := range cols {
+		start, end := c[0], c[1]
+		if end < 0 || end > len(line) {
+			end = len(line)
+		}
+		if start > end {
+			start = end
+		}
+		out = append(out, strings.TrimSpace(string(line[start:end])))
+	}
+	return out
+}
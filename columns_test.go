@@ -0,0 +1,84 @@
+package main
+
+import "testing"
+
+var alignedTests = []struct {
+	corpus string
+	header []string
+	out    []map[string]string
+}{
+	{
+		corpus: "" +
+			"USER         PID %CPU COMMAND\n" +
+			"root           1  0.0 /sbin/init splash\n" +
+			"jimmy     123456 12.5 vim x y\n",
+		out: []map[string]string{
+			{"USER": "root", "PID": "1", "%CPU": "0.0", "COMMAND": "/sbin/init splash"},
+			{"USER": "jimmy", "PID": "123456", "%CPU": "12.5", "COMMAND": "vim x y"},
+		},
+	},
+	{
+		corpus: "" +
+			"CONTAINER ID   IMAGE   CREATED\n" +
+			"0123456789ab   nginx   2 hours ago\n",
+		out: []map[string]string{
+			{"CONTAINER ID": "0123456789ab", "IMAGE": "nginx", "CREATED": "2 hours ago"},
+		},
+	},
+	{
+		corpus: "" +
+			"CONTAINER ID   IMAGE   CREATED\n" +
+			"abc            nginx   2 hours ago\n",
+		out: []map[string]string{
+			{"CONTAINER ID": "abc", "IMAGE": "nginx", "CREATED": "2 hours ago"},
+		},
+	},
+	{
+		corpus: "" +
+			"NAME   READY   NODE     NOMINATED NODE   READINESS GATES\n" +
+			"web    1/1     node1    <none>           <none>\n",
+		out: []map[string]string{
+			{"NAME": "web", "READY": "1/1", "NODE": "node1", "NOMINATED NODE": "<none>", "READINESS GATES": "<none>"},
+		},
+	},
+	{
+		corpus: "" +
+			"NAME  AGE\n" +
+			"a b   1d\n",
+		header: []string{"n", "a"},
+		out: []map[string]string{
+			{"n": "a b", "a": "1d"},
+		},
+	},
+}
+
+func TestColumnsAligned(t *testing.T) {
+	for i, v := range alignedTests {
+		ret, err := Columns(v.header, RS, "", rdr(v.corpus))
+		failIf(t, i, err)
+		failIf(t, i, listMapEquals(v.out, ret.([]map[string]string)))
+	}
+}
+
+func TestColumnsDuplicateHeader(t *testing.T) {
+	if _, err := Columns(nil, RS, "", rdr("A  B  A\n1  2  3\n")); err == nil {
+		t.Error("expected error for duplicate column names")
+	}
+}
+
+func TestColumnsWidths(t *testing.T) {
+	ret, err := Columns(nil, RS, "1-3,4-5,6-", rdr("abcde fg\n12 45 7\n"))
+	failIf(t, 0, err)
+	recs, err := fields(ret)
+	failIf(t, 0, err)
+	failIf(t, 0, listListEquals([][]string{
+		{"abc", "de", "fg"},
+		{"12", "45", "7"},
+	}, recs))
+
+	for _, W := range []string{"0-3", "3-1", "a-b", ""} {
+		if _, err := widths(W); err == nil {
+			t.Errorf("expected error for invalid ranges %q", W)
+		}
+	}
+}
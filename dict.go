@@ -0,0 +1,195 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+func dict(kvs ...interface{}) (map[string]interface{}, error) {
+	if len(kvs)%2 != 0 {
+		return nil, errors.New("dict requires an even number of arguments")
+	}
+	m := make(map[string]interface{}, len(kvs)/2)
+	for i := 0; i < len(kvs); i += 2 {
+		k, ok := kvs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict key %v is not a string", kvs[i])
+		}
+		m[k] = kvs[i+1]
+	}
+	return m, nil
+}
+
+func list(vs ...interface{}) []interface{} {
+	return append([]interface{}{}, vs...)
+}
+
+func appendList(l interface{}, vs ...interface{}) ([]interface{}, error) {
+	is, err := items(l)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, 0, len(is)+len(vs))
+	out = append(out, is...)
+	return append(out, vs...), nil
+}
+
+func prependList(l interface{}, vs ...interface{}) ([]interface{}, error) {
+	is, err := items(l)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, 0, len(is)+len(vs))
+	out = append(out, vs...)
+	return append(out, is...), nil
+}
+
+func mapOf(m interface{}) (map[string]interface{}, error) {
+	//a copy of the entries of anything map-like with names for keys
+	switch m := m.(type) {
+	case nil:
+		return map[string]interface{}{}, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			out[k] = v
+		}
+		return out, nil
+	case *row:
+		out := make(map[string]interface{}, len(m.Header))
+		for _, h := range m.Header {
+			out[h] = m.Get(h)
+		}
+		return out, nil
+	}
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("can't use type %s as map", v.Type())
+	}
+	out := make(map[string]interface{}, v.Len())
+	for _, k := range v.MapKeys() {
+		out[k.String()] = v.MapIndex(k).Interface()
+	}
+	return out, nil
+}
+
+func mutableMap(m interface{}, key interface{}) (reflect.Value, reflect.Value, error) {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		if _, ok := m.(*row); ok {
+			return v, v, errors.New("can't modify a row, use merge to copy it into a map")
+		}
+		return v, v, fmt.Errorf("can't use type %s as map", reflect.TypeOf(m))
+	}
+	if v.IsNil() {
+		return v, v, errors.New("can't modify a missing map")
+	}
+	k, ok := key.(string)
+	if !ok {
+		return v, v, fmt.Errorf("map key %v is not a string", key)
+	}
+	return v, reflect.ValueOf(k).Convert(v.Type().Key()), nil
+}
+
+func setKey(m, key, value interface{}) (string, error) {
+	v, k, err := mutableMap(m, key)
+	if err != nil {
+		return "", err
+	}
+	et := v.Type().Elem()
+	x := reflect.ValueOf(value)
+	switch {
+	case !x.IsValid():
+		x = reflect.Zero(et)
+	case et.Kind() == reflect.String && x.Kind() != reflect.String:
+		//so that the fields of CSV and the like stay text
+		x = reflect.ValueOf(text(value)).Convert(et)
+	case !x.Type().AssignableTo(et):
+		return "", fmt.Errorf("can't use %v as value of %s", value, v.Type())
+	}
+	v.SetMapIndex(k, x)
+	return "", nil
+}
+
+func unsetKey(m, key interface{}) (string, error) {
+	v, k, err := mutableMap(m, key)
+	if err != nil {
+		return "", err
+	}
+	v.SetMapIndex(k, reflect.Value{})
+	return "", nil
+}
+
+func merge(ms ...interface{}) (map[string]interface{}, error) {
+	out := map[string]interface{}{}
+	for _, m := range ms {
+		e, err := mapOf(m)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range e {
+			out[k] = v
+		}
+	}
+	return out, nil
+}
+
+func keys(m interface{}) ([]string, error) {
+	e, err := mapOf(m)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(e))
+	for k := range e {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func values(m interface{}) ([]interface{}, error) {
+	e, err := mapOf(m)
+	if err != nil {
+		return nil, err
+	}
+	ks, _ := keys(e)
+	out := make([]interface{}, len(ks))
+	for i, k := range ks {
+		out[i] = e[k]
+	}
+	return out, nil
+}
+
+func hasKey(m, key interface{}) (bool, error) {
+	k, ok := key.(string)
+	if !ok {
+		return false, fmt.Errorf("map key %v is not a string", key)
+	}
+	e, err := mapOf(m)
+	if err != nil {
+		return false, err
+	}
+	_, ok = e[k]
+	return ok, nil
+}
+
+func contains(l, item interface{}) (bool, error) {
+	//items are compared as text, as with where
+	if s, ok := l.(string); ok {
+		return strings.Contains(s, text(item)), nil
+	}
+	is, err := items(l)
+	if err != nil {
+		return false, err
+	}
+	want := text(item)
+	for _, it := range is {
+		if text(it) == want {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDict(t *testing.T) {
+	d, err := dict("b", 2, "a", 1)
+	failIf(t, 0, err)
+	if _, err := dict("a"); err == nil {
+		t.Error("expected error for odd number of arguments")
+	}
+
+	_, err = setKey(d, "c", 3)
+	failIf(t, 0, err)
+	_, err = unsetKey(d, "b")
+	failIf(t, 0, err)
+	ks, err := keys(d)
+	failIf(t, 0, err)
+	failIf(t, 0, listEquals(0, []string{"a", "c"}, ks))
+	vs, err := values(d)
+	failIf(t, 0, err)
+	if !reflect.DeepEqual(vs, []interface{}{1, 3}) {
+		t.Errorf("expected values [1 3], got %v", vs)
+	}
+
+	m, err := merge(d, map[string]string{"a": "x"})
+	failIf(t, 0, err)
+	if m["a"] != "x" || m["c"] != 3 {
+		t.Errorf("unexpected merge %v", m)
+	}
+	if d["a"] != 1 {
+		t.Error("merge modified its argument")
+	}
+	if ok, _ := hasKey(m, "c"); !ok {
+		t.Error("expected key c")
+	}
+}
+
+func TestDictCSV(t *testing.T) {
+	ret, err := CSV(splitHeader("x,y"), rdr("1,2\n"))
+	failIf(t, 0, err)
+	m := ret.([]map[string]string)[0]
+	_, err = setKey(m, "z", 3)
+	failIf(t, 0, err)
+	if m["z"] != "3" {
+		t.Errorf("expected z to be text, got %q", m["z"])
+	}
+
+	*Ordered = true
+	defer func() { *Ordered = false }()
+	ret, err = CSV(splitHeader("x,y"), rdr("1,2\n"))
+	failIf(t, 0, err)
+	r := ret.(table)[0]
+	if _, err := setKey(r, "z", 3); err == nil {
+		t.Error("expected error modifying row")
+	}
+	ks, err := keys(r)
+	failIf(t, 0, err)
+	failIf(t, 0, listEquals(0, []string{"x", "y"}, ks))
+}
+
+func TestList(t *testing.T) {
+	l := list(2)
+	a, err := appendList(l, 3)
+	failIf(t, 0, err)
+	p, err := prependList(a, 1)
+	failIf(t, 0, err)
+	if !reflect.DeepEqual(p, []interface{}{1, 2, 3}) || len(l) != 1 {
+		t.Errorf("unexpected list %v from %v", p, l)
+	}
+	if ok, _ := contains(p, "3"); !ok {
+		t.Error("expected list to contain 3")
+	}
+	if ok, _ := contains("abc", "bc"); !ok {
+		t.Error("expected string to contain bc")
+	}
+}
@@ -3,12 +3,14 @@
 //Input
 //
 //The input to the template comes from stdin.
-//It is parsed in one of five ways.
+//It is parsed in one of ten ways.
 //
 //The default is to split stdin into records and fields, using the -R and -F
 //flags respectively, similar to awk(1), and dot is set to a list of records
 //(see below).
 //If header is set, dot is a list of maps with the specified names as keys.
+//If the -header-line flag is specified, or the header is -, the fields of
+//the first record are used as the header.
 //
 //If the -L flag is specified stdin is broken into records as with the default,
 //but the fields are defined by the capture groups of the regular expression
@@ -19,26 +21,110 @@
 //Otherwise, dot is a list of records (see below) of the capture groups' values.
 //If header is set, dot is a list of maps with the specified names as keys,
 //overriding any names from capture groups.
+//If the -header-line flag is specified, or the header is -, the capture groups'
+//values of the first record are used as the header.
+//The first record must match -L.
+//
+//If the -W or -columns flag is specified, stdin is broken into records as
+//with the default, but the fields are defined by their position in the record,
+//as in the aligned tabular output of commands like ps(1), so that fields may
+//contain whitespace.
+//Fields are trimmed of leading and trailing whitespace.
+//The -W flag specifies the positions as a comma-separated list of 1-based,
+//inclusive ranges of characters, such as 1-8,9-20,21- where the last range
+//continues to the end of the record.
+//Otherwise, dot is a list of records, or maps if there is a header,
+//as with the default.
+//The -columns flag uses the first record as the header and infers the
+//positions from those of the names in the header.
+//A column ends at the last position before the next name that is whitespace
+//in every record, so that right-aligned values wider than their name are kept
+//whole, and names with no such position between them are treated as a single
+//name, such as "CONTAINER ID" in the output of docker ps.
+//Names separated by a single space are also a single name unless some record
+//has a value under the second name, so that "NOMINATED NODE" stays whole
+//even when its values are shorter than NOMINATED.
+//If the names in the header line are not unique, execution halts.
+//Dot is a list of maps with the header for each column as the key,
+//or the names from -header if specified.
 //
 //If the -csv flag is specified, stdin is treated as a CSV file, as recognized
 //by the encoding/csv package.
-//If the -header flag is not specified, the first record is used as the header.
+//If the -header flag is not specified, or is -, the first record is used as
+//the header.
 //Dot is set to a list of maps, with the header for each column as the key.
 //
 //If the -json flag is specified, stdin is treated as JSON.
 //Dot is set as the decoded JSON.
 //
+//If the -jsonl flag is specified, stdin is treated as a stream of JSON values,
+//usually one per line as in JSON Lines or NDJSON.
+//Dot is set to a list of the decoded values.
+//If a value cannot be decoded, the error reports its line.
+//
+//If the -yaml flag is specified, stdin is treated as YAML.
+//Dot is set as the decoded YAML, or, if stdin is a stream of more than one
+//document, a list of the decoded documents.
+//All maps have string keys.
+//
+//If the -toml flag is specified, stdin is treated as TOML.
+//Dot is set as the decoded TOML table.
+//
+//If the -xml flag is specified, stdin is treated as an XML document.
+//Dot is set to the root element (see below).
+//
 //If the -no-stdin flag is specified, stdin is not read.
 //Dot is not set.
 //
+//Streaming
+//
+//By default all of stdin is read before the template is executed.
+//If the -stream flag is specified, with -F or -L, stdin is instead read one
+//record at a time and the main template is executed once for each record as
+//soon as it is read, with dot set to that record, or map if there is
+//a header, so that txt can process unbounded input such as the output
+//of tail -f.
+//
+//If a template named BEGIN is defined, it is executed before the first record
+//is read.
+//If a template named END is defined, it is executed after the last record.
+//Dot is not set for either.
+//
+//Types
+//
+//Any name in the -header flag, or a header given to one of the parse functions
+//below, may be followed by a colon and a type, such as
+//	-header=name,size:int,ratio:float,when:time
+//so that the values of that field are converted to that type instead
+//of left as strings.
+//The types are string, int, float, bool, and time.
+//Times are RFC 3339 unless followed by = and a layout, as with parseTime,
+//for example time=2006-01-02 or time=%d/%b/%Y.
+//Empty fields are converted to the zero value of the type.
+//If any other field cannot be converted, execution halts with an error
+//reporting the column of the field and the number of its record, counting
+//from 0 as in the other errors reading the input.
+//For -csv that is the number of the row after the header, otherwise it is
+//one less than the NR the record would have.
+//
 //Records
 //
 //When using -F or -L without a header, or in the case of -L without named
 //capture groups, dot is a list of records.
 //
-//Each record has two fields, Fields and Line.
+//Each record has the fields Fields, Line, NR, FILENAME, Start, and End.
 //Line is the complete unaltered input of that record.
 //Fields are the values of each field in that record.
+//NR is the number of the record in the input, starting from 1,
+//and counting records that do not match -L and the empty records between
+//consecutive matches of -R, which are otherwise skipped.
+//If -R is \n, NR is the line number.
+//FILENAME is the name of the input, stdin for stdin, or, for the parse
+//functions below, which are given the contents of the input, the optional
+//name given after the contents, or the empty string.
+//Start and End are the byte offsets of the beginning and end of Line
+//in the input.
+//Records also have a method NF that returns the number of fields.
 //If dot is a record
 //	{{.}}
 //is the same as
@@ -52,6 +138,47 @@
 //is equivalent to
 //	{{index . n}}
 //
+//Rows
+//
+//When there is a header, each record is a map by default, so the order of
+//the header is lost and ranging over a map is in the sorted order of its keys.
+//If the -ordered flag is specified, each record is instead a row that keeps
+//the order of the header.
+//This applies to the input and all the parse functions below that use
+//a header.
+//
+//Each row has two fields, Header and Fields.
+//Header is the list of names in the header, in order.
+//Fields are the values of each field in that row, in the same order.
+//Rows have a method Get that takes a name and returns the value of that field,
+//converted to its type, if any, or the empty string, and a method F like that
+//of records.
+//If the header is a,b then
+//	{{.Get "b"}}
+//is equivalent to
+//	{{.F 1}}
+//
+//A list of rows has a method Header that returns the header, or nothing if the
+//list is empty, so that, for example, a CSV file can be regenerated with
+//	{{join "," .Header}}
+//	{{range .}}{{join "," .Fields}}
+//	{{end}}
+//
+//XML
+//
+//Each XML element is decoded as a map with the following keys:
+//	#name      the local name of the element
+//	#text      all the text directly within the element, trimmed of
+//	           leading and trailing whitespace
+//	#children  a list of all child elements, in document order
+//	@attr      the value of the attribute attr, for each attribute
+//	child      a list of all child elements named child, for each child
+//Children are always in a list, even if there is only one,
+//so for the document
+//	<project><version>1.0</version></project>
+//the version is
+//	{{index (index .version 0) "#text"}}
+//
 //Templates
 //
 //The templating language is documented at http://golang.org/pkg/text/template
@@ -68,6 +195,45 @@
 //If the -e flag is used to define an inline template, it is always the main
 //template, and the -template flag is illegal.
 //
+//Any command line arguments after a -- are not templates but are passed to
+//the templates as a list of strings returned by the function
+//	args
+//so that a template file beginning with
+//	#!/usr/bin/env txt
+//may be made executable and run as
+//	./script -- arg1 arg2
+//with {{index args 0}} being arg1.
+//If there is no --, args is empty.
+//
+//Variables
+//
+//The -var flag defines a variable as name=value and may be repeated.
+//The -vars-file flag reads variables from a file that is either a JSON
+//object or lines of name=value, as in a .env file, where blank lines and
+//lines beginning with # are skipped, a leading export is ignored, and values
+//may be quoted.
+//Variables given with -var override those in -vars-file.
+//Variables are available in every template, regardless of input, by the
+//functions
+//	var name
+//which returns the variable name or halts execution if it is not defined,
+//and
+//	vars
+//which returns a dictionary of all variables, so that
+//	{{with index vars "title"}}{{.}}{{else}}Untitled{{end}}
+//handles an optional variable.
+//
+//Safe mode
+//
+//If the -safe flag is specified, the functions that run commands, read files,
+//or read the environment are not defined, so that templates from elsewhere
+//cannot use them.
+//These are exec, execResult, pipe, sh, shPipe, read, env, parse, and the
+//parse functions for each format, such as parseJSON.
+//A template that uses one of them fails to parse with an error naming it.
+//The -allow flag takes a comma-separated list of these functions to define
+//anyway, such as -allow=read,env, and implies -safe.
+//
 //Regular Expressions
 //
 //All regular expressions are RE2 regular expression with the Perl syntax and
@@ -103,18 +269,54 @@
 //		or the file cannot be opened.
 //		Dot is set to the contents of the JSON file as with -json.
 //
-//	parseLine header FS LP filename
+//	parseJSONL filename
+//		Read the stream of JSON values in file into a list or halt execution
+//		if decoding fails or the file cannot be opened.
+//		Dot is set to the contents of the file as with -jsonl.
+//
+//	parseTOML filename
+//		Read the TOML encoded file into dot or halt execution if decoding fails
+//		or the file cannot be opened.
+//		Dot is set to the contents of the TOML file as with -toml.
+//
+//	parseXML filename
+//		Read the XML document in file into dot or halt execution if decoding
+//		fails or the file cannot be opened.
+//		Dot is set to the root element of the document as with -xml.
+//
+//	parseYAML filename
+//		Read the YAML encoded file into dot or halt execution if decoding fails
+//		or the file cannot be opened.
+//		Dot is set to the contents of the YAML file as with -yaml.
+//
+//	parseLine header FS LP filename name?
 //		Read filename with line pattern splitting as specified by the RS and
 //		LP regular expressions, and an optional header header.
 //		If header is not "", the names in header will be used as the field names.
+//		If header is "-", the first record is used as the header.
 //		If RS or LP are "", the respective value of -R or -L is used.
 //
-//	parse header RS FS filename
+//	parseColumns RS W header filename name?
+//		Read filename with column splitting as specified by the RS regular
+//		expression and the ranges in W, as with -W, and an optional header.
+//		If W is "", the positions are inferred from the header line as with
+//		-columns.
+//		If header is not "", the names in header will be used as the field names.
+//		If header is "-", the first record is used as the header.
+//		If RS is "", the value of -R is used.
+//
+//	parse header RS FS filename name?
 //		Read filename with the default record and file splitting as specified
 //		by the RS and FS regular expressions, and optional header header.
 //		If header is not "", the names in header will be used as the field names.
+//		If header is "-", the first record is used as the header.
 //		If RS or FS are "", the respective value of -R or -F is used.
 //
+//	The records of parseLine, parseColumns, and parse have name as their
+//	FILENAME, so that
+//		{{range parse "" "" "" (read "app.log") "app.log"}}{{.FILENAME}}:{{.NR}}{{end}}
+//	reports where each record came from.
+//
 //	read filename
 //		Read filename completely as a single string.
 //		Execution halts if the file cannot be read.
@@ -183,10 +385,291 @@
 //		Replace all substrings in string matching pattern by spec.
 //		Execution halts if pattern is not a valid regular expression.
 //
+//	replaceWith pattern name string
+//		Replace all substrings in string matching pattern by the output of
+//		the template name, which is executed for each match with dot set to
+//		the match followed by the values of its capture groups, as with
+//		submatch.
+//		For example, given
+//			{{define "up"}}{{upper (index . 1)}}{{end}}
+//		then
+//			{{replaceWith `\$(\w+)` "up" .}}
+//		replaces each $word in dot with WORD.
+//		Execution halts if pattern is not a valid regular expression
+//		or there is no template name.
+//		With -html, the output of name is not escaped again, but the rest
+//		of string is escaped.
+//
 //	split pattern string
 //		Split string into a list of substrings separated by pattern.
 //		Execution halts if pattern is not a valid regular expression.
 //
+//	submatch pattern string
+//		Returns the first match of pattern in string followed by the
+//		values of its capture groups, or nothing if there is no match.
+//		Execution halts if pattern is not a valid regular expression.
+//
+//	submatchAll pattern string
+//		Returns a list of every match of pattern in string, each as with
+//		submatch.
+//		Execution halts if pattern is not a valid regular expression.
+//
+//	namedSubmatch pattern string
+//		Returns a dictionary of the named capture groups of pattern to their
+//		values in the first match in string, or nothing if there is no match,
+//		as with -L, so that
+//			{{with namedSubmatch `(?P<user>[^@]+)@(?P<host>.+)` .email}}{{.host}}{{end}}
+//		prints the host of an email address.
+//		Execution halts if pattern is not a valid regular expression.
+//
+//	add number+
+//		Return the sum of the numbers.
+//		Numbers may be integers, floating point numbers, or strings
+//		containing either, as with every field of the input without
+//		a typed header.
+//		The result is an integer unless any of the numbers are floating point.
+//		Execution halts if any argument is not a number.
+//		This applies to all the numeric functions below.
+//
+//	sub a b
+//		Return a minus b.
+//
+//	mul number+
+//		Return the product of the numbers.
+//
+//	div a b
+//		Return a divided by b as a floating point number.
+//		Execution halts if b is zero.
+//
+//	mod a b
+//		Return the remainder of a divided by b.
+//		Execution halts if b is zero.
+//
+//	min number+
+//	min list field?
+//		Return the smallest number or, given a list, the smallest item
+//		or field of an item in the list as with sum.
+//		Execution halts if the list is empty.
+//
+//	max number+
+//	max list field?
+//		As min, but the largest.
+//
+//	abs number
+//		Return the absolute value of number.
+//
+//	round number places?
+//		Return number rounded to the nearest integer, or, if places is
+//		specified, a floating point number rounded to that many decimal places.
+//
+//	floor number
+//		Return the greatest integer less than or equal to number.
+//
+//	ceil number
+//		Return the least integer greater than or equal to number.
+//
+//	sum list field?
+//		Return the sum of the items in list.
+//		If field is specified, it is the sum of that field of each item,
+//		where field is a name for maps and rows, or an index for records,
+//		rows, and lists, as in
+//			{{sum . "bytes"}}
+//		This applies to all the list functions below.
+//
+//	mean list field?
+//		Return the arithmetic mean.
+//		Execution halts if the list is empty.
+//		This applies to median, percentile, and stddev as well.
+//
+//	median list field?
+//		Return the median.
+//
+//	percentile list p field?
+//		Return the pth percentile, interpolating between the closest values.
+//		p must be between 0 and 100.
+//
+//	stddev list field?
+//		Return the population standard deviation.
+//
+//	count list field?
+//		Return the number of items in list or, if field is specified, the
+//		number of items where that field is not empty.
+//
+//	sort list options?
+//		Return a copy of list sorted by the value of each item.
+//		options are as with sortBy.
+//
+//	sortBy list key+
+//		Return a copy of list sorted by each key in turn, keeping items
+//		with equal keys in their original order.
+//		Each key is a field as with sum, optionally followed by a colon and
+//		options like those of sort(1): n to sort numerically, V to sort
+//		naturally, so that runs of digits compare numerically, and r to
+//		reverse the order, as in
+//			{{sortBy . "host" "bytes:nr"}}
+//		A field can be given as a string for records, such as "2:n",
+//		and an empty field, such as ":n", is the item itself.
+//		Without n or V, numbers and times compare as such and anything else
+//		as text.
+//		Empty fields sort first, or last if reversed.
+//		With n, execution halts if a field is not a number.
+//
+//	groupBy list field
+//		Return a list of the groups of items in list with equal values of
+//		field, as with sum, in the order the values first appear.
+//		Each group has the fields Key, the value of field, and Members,
+//		a list of the items in the group, as in
+//			{{range groupBy . "status"}}{{.Key}}: {{len .Members}}
+//			{{end}}
+//
+//	countBy list field
+//		Return a list of the number of items in list with each value of field,
+//		in the order the values first appear.
+//		Each has the fields Key, the value of field, and Count, so
+//		the most common values first is
+//			{{sortBy (countBy . "host") "Count:nr"}}
+//
+//	uniq list
+//		Return a copy of list without any repeated items, keeping the first.
+//		Records are the same if their lines are.
+//
+//	uniqBy list field
+//		Return a copy of list with only the first item for each value of field.
+//
+//	where list field operator? value
+//		Return a copy of list with only the items whose field, as with sum,
+//		satisfies operator and value.
+//		The operators are
+//			== != equal, or not, as text, the default if there is no operator
+//			~ !~  match the regular expression value, or not
+//			< <= > >= compare as numbers
+//		For example
+//			{{range where . "bytes" ">" 1000}}
+//		Execution halts if value is not a number or valid regular expression,
+//		as required, or if a field is not a number for numeric comparison.
+//
+//	reject list field operator? value
+//		As where, but the items that do not satisfy operator and value.
+//
+//	pluck list field
+//		Return a list of field, as with sum, of every item in list, as text,
+//		such as
+//			{{join ", " (pluck . "host")}}
+//
+//	map list field+
+//		Return a list of dictionaries of only the given fields, as with sum,
+//		of every item in list, keyed by field, such as
+//			{{toJSON (map . "host" "bytes")}}
+//		Execution halts if an item does not have fields, such as a string.
+//
+//	dict key value ...
+//		Return a map of each string key to the value following it.
+//
+//	list value ...
+//		Return a list of the values.
+//
+//	append list value ...
+//	prepend list value ...
+//		Return a new list of the items of list with the values added to
+//		the end or beginning, respectively.
+//		The original list is not modified.
+//
+//	set map key value
+//	unset map key
+//		Set or remove the key in map, which may be a map from dict,
+//		JSON, or a header, but not a row from -ordered.
+//		Values set in a map from CSV or the like are converted to text.
+//		Nothing is returned, so they may be used as actions, for example
+//			{{$seen := dict}}
+//			{{range .}}{{set $seen .host true}}{{end}}
+//
+//	merge map ...
+//		Return a new map with the keys of each map, with later maps taking
+//		precedence over earlier ones.
+//		Rows from -ordered may be merged into a map.
+//
+//	keys map
+//	values map
+//		Return the keys of map, or the values of map, in the sorted order of
+//		the keys.
+//
+//	hasKey map key
+//		Report whether map has key.
+//
+//	contains list item
+//		Report whether any item in list is item, compared as text.
+//		If list is a string, report whether item is a substring.
+//
+//	set name value
+//	get name
+//		Set or get a variable in the store shared by every template,
+//		which, unlike template variables, keeps its value outside of the
+//		range or template where it is set.
+//		The store lasts for the whole execution, including every record
+//		with -stream.
+//		Getting a variable that has not been set returns nothing.
+//
+//	incr name number?
+//		Add number, or 1, to the variable name in the store,
+//		which starts at 0.
+//
+//	push name value ...
+//		Append the values to the list in the variable name in the store.
+//		For example, to print a total after the records
+//			{{range .}}{{incr "bytes" (.F 1)}}{{.}}
+//			{{end}}total: {{get "bytes"}}
+//
+//		As with set, incr and push return nothing.
+//
+//	now
+//		Return the current time.
+//
+//	parseTime layout string
+//		Parse string as a time in layout.
+//		The layout may be a layout as documented at
+//		http://golang.org/pkg/time/#pkg-constants, such as 2006-01-02,
+//		the name of one of the layouts there, such as RFC1123,
+//		apache (or clf) for the log format 02/Jan/2006:15:04:05 -0700,
+//		or, if it contains %, a strftime(3) format such as %Y-%m-%d.
+//		The strftime conversions are %a %A %b %B %h %d %e %j %m %y %Y %H %I %M
+//		%S %p %z %Z %F %D %T %R %n %t and %%.
+//
+//	formatTime layout time
+//		Format time in layout, as with parseTime.
+//
+//	Anywhere a time is expected, an RFC 3339 string or a number of seconds
+//	since the Unix epoch may be used as well, and, anywhere a duration is
+//	expected, a string such as 1h30m or a number of seconds may be used.
+//	Times compare as such with sort and sortBy.
+//
+//	unix time
+//		Return time as the number of seconds since the Unix epoch.
+//
+//	fromUnix seconds
+//		Return the time that is seconds since the Unix epoch.
+//
+//	parseDuration string
+//		Parse string as a duration, such as 1h30m or 250ms.
+//		Durations print in the same form.
+//
+//	duration seconds
+//		Return the duration of seconds.
+//
+//	seconds duration
+//		Return duration as a number of seconds.
+//
+//	addTime duration time
+//		Return time plus duration, which may be negative, so that
+//			{{now | addTime "-24h"}}
+//		is a day ago.
+//
+//	subTime time1 time2
+//		Return the duration from time2 to time1.
+//
+//	inZone zone time
+//		Return time in zone, which is UTC, Local, or the name of a location
+//		in the IANA Time Zone database, such as America/New_York.
+//
 //	env key
 //		Returns the environment variable key or "".
 //
@@ -194,8 +677,42 @@
 //		Execute command name with args. Stdin is nil.
 //		Stderr shares the stderr of txt(1).
 //		Stdout is returned as a string.
+//		If the -strict flag is specified and the command cannot be run,
+//		exits with a non-zero status, or times out, execution halts with
+//		the command's stderr in the error.
+//		Otherwise, whatever the command wrote to stdout is returned.
+//		If the -timeout flag is specified, the command is killed if it runs
+//		longer than the given duration, such as 10s.
 //
 //	pipe name args* input
 //		Execute command name with args with input as stdin.
 //		Otherwise, like exec.
+//
+//	execResult name args*
+//		Execute command name with args, as with exec, but return the result
+//		with the fields
+//			Stdout   what the command wrote to stdout
+//			Stderr   what the command wrote to stderr
+//			ExitCode the exit status of the command, or -1 if it was killed
+//			Duration how long the command ran
+//		so that failures may be handled in the template, regardless of
+//		-strict.
+//		The result prints as Stdout.
+//		Execution halts only if the command cannot be run.
+//
+//	sh command options?
+//		Run the command line command with /bin/sh -c, so that it may use
+//		pipelines, redirections, and the like, otherwise like exec.
+//		The options are a dictionary, as from dict, of
+//			env   a dictionary of environment variables to add to, or
+//			      override in, the environment of txt(1)
+//			dir   the working directory of the command
+//			input the stdin of the command
+//		For example
+//			{{sh "ls | wc -l" (dict "dir" .path)}}
+//
+//	shPipe command options? input
+//		As sh with input as stdin, so that
+//			{{.body | shPipe "sort | uniq -c"}}
+//		counts the unique lines of .body.
 package main
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func text(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func predicate(args []interface{}) (func(interface{}) (bool, error), error) {
+	//args is value or op value
+	var op string
+	var val interface{}
+	switch len(args) {
+	case 1:
+		op, val = "==", args[0]
+	case 2:
+		var ok bool
+		if op, ok = args[0].(string); !ok {
+			return nil, fmt.Errorf("operator must be a string, not %v", args[0])
+		}
+		val = args[1]
+	default:
+		return nil, fmt.Errorf("expected value or operator and value, given %d arguments", len(args))
+	}
+
+	switch op {
+	case "==", "!=":
+		want := text(val)
+		return func(v interface{}) (bool, error) {
+			return (text(v) == want) == (op == "=="), nil
+		}, nil
+	case "~", "!~":
+		r, err := cmpl(text(val))
+		if err != nil {
+			return nil, err
+		}
+		return func(v interface{}) (bool, error) {
+			return r.MatchString(text(v)) == (op == "~"), nil
+		}, nil
+	case "<", "<=", ">", ">=":
+		want, err := toNumber(val)
+		if err != nil {
+			return nil, err
+		}
+		return func(v interface{}) (bool, error) {
+			n, err := toNumber(v)
+			if err != nil {
+				return false, err
+			}
+			c := compareFloats(n.float(), want.float())
+			switch op {
+			case "<":
+				return c < 0, nil
+			case "<=":
+				return c <= 0, nil
+			case ">":
+				return c > 0, nil
+			}
+			return c >= 0, nil
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", op)
+}
+
+func filter(list, key interface{}, args []interface{}, keep bool) (interface{}, error) {
+	p, err := predicate(args)
+	if err != nil {
+		return nil, err
+	}
+	vs, err := fieldOf(list, []interface{}{key})
+	if err != nil || vs == nil {
+		return list, err
+	}
+	var idx []int
+	for i, v := range vs {
+		ok, err := p(v)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %s", i, err)
+		}
+		if ok == keep {
+			idx = append(idx, i)
+		}
+	}
+	return subset(list, idx), nil
+}
+
+func where(list, key interface{}, args ...interface{}) (interface{}, error) {
+	return filter(list, key, args, true)
+}
+
+func reject(list, key interface{}, args ...interface{}) (interface{}, error) {
+	return filter(list, key, args, false)
+}
+
+func pluck(list, key interface{}) ([]string, error) {
+	vs, err := fieldOf(list, []interface{}{key})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, len(vs))
+	for i, v := range vs {
+		out[i] = text(v)
+	}
+	return out, nil
+}
+
+func project(list interface{}, keys ...interface{}) ([]map[string]interface{}, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("map requires at least one field")
+	}
+	is, err := items(list)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]map[string]interface{}, len(is))
+	for i, it := range is {
+		m := make(map[string]interface{}, len(keys))
+		for _, k := range keys {
+			if m[text(k)], err = field(it, k); err != nil {
+				return nil, fmt.Errorf("item %d: %s", i, err)
+			}
+		}
+		out[i] = m
+	}
+	return out, nil
+}
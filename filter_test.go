@@ -0,0 +1,66 @@
+package main
+
+import "testing"
+
+func TestWhere(t *testing.T) {
+	ret, err := Split(splitHeader("path,bytes"), RS, FS, rdr("/api/a 10\n/b 2000\n/api/c 300\n"))
+	failIf(t, 0, err)
+
+	var tests = []struct {
+		args []interface{}
+		keep bool
+		key  string
+		out  []string
+	}{
+		{[]interface{}{"/b"}, true, "path", []string{"/b"}},
+		{[]interface{}{"~", "^/api"}, true, "path", []string{"/api/a", "/api/c"}},
+		{[]interface{}{"!~", "^/api"}, true, "path", []string{"/b"}},
+		{[]interface{}{">", 100}, true, "bytes", []string{"/b", "/api/c"}},
+		{[]interface{}{"<=", "10"}, false, "bytes", []string{"/b", "/api/c"}},
+		{[]interface{}{"!=", "10"}, true, "bytes", []string{"/b", "/api/c"}},
+	}
+	for i, v := range tests {
+		out, err := filter(ret, v.key, v.args, v.keep)
+		failIf(t, i, err)
+		paths, err := pluck(out, "path")
+		failIf(t, i, err)
+		failIf(t, i, listEquals(i, v.out, paths))
+	}
+
+	if _, err := where(ret, "path", ">", 1); err == nil {
+		t.Error("expected error comparing non-numbers")
+	}
+	if _, err := where(ret, "path", "<>", 1); err == nil {
+		t.Error("expected error for unknown operator")
+	}
+}
+
+func TestPluckRecords(t *testing.T) {
+	ret, err := Split(nil, RS, FS, rdr("a 1\nb 2\n"))
+	failIf(t, 0, err)
+	out, err := pluck(ret, -1)
+	failIf(t, 0, err)
+	failIf(t, 0, listEquals(0, []string{"1", "2"}, out))
+}
+
+func TestMap(t *testing.T) {
+	ret, err := Split(splitHeader("path,bytes,code"), RS, FS, rdr("/a 10 200\n/b 20 404\n"))
+	failIf(t, 0, err)
+	out, err := project(ret, "path", "code")
+	failIf(t, 0, err)
+	if len(out) != 2 || len(out[1]) != 2 || out[1]["path"] != "/b" || out[1]["code"] != "404" {
+		t.Errorf("unexpected projection %v", out)
+	}
+
+	recs, err := Split(nil, RS, FS, rdr("a 1\n"))
+	failIf(t, 0, err)
+	out, err = project(recs, 1)
+	failIf(t, 0, err)
+	if out[0]["1"] != "1" {
+		t.Errorf("unexpected projection %v", out)
+	}
+
+	if _, err := project(ret); err == nil {
+		t.Error("expected error without fields")
+	}
+}
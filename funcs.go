@@ -10,6 +10,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var funcs = map[string]interface{}{
@@ -87,7 +88,19 @@ var funcs = map[string]interface{}{
 	"parseJSON": func(input string) (interface{}, error) {
 		return JSON(rdr(input))
 	},
-	"parseLine": func(RS, LP, header, input string) (interface{}, error) {
+	"parseJSONL": func(input string) (interface{}, error) {
+		return JSONL(rdr(input))
+	},
+	"parseTOML": func(input string) (interface{}, error) {
+		return TOML(rdr(input))
+	},
+	"parseXML": func(input string) (interface{}, error) {
+		return XML(rdr(input))
+	},
+	"parseYAML": func(input string) (interface{}, error) {
+		return YAML(rdr(input))
+	},
+	"parseLine": func(RS, LP, header, input string, name ...string) (interface{}, error) {
 		if RS == "" {
 			RS = *RecordSeparator
 		}
@@ -95,9 +108,24 @@ var funcs = map[string]interface{}{
 			LP = *LinePattern
 		}
 		hdr := splitHeader(header)
-		return SubmatchSplit(hdr, RS, LP, rdr(input))
+		r, err := named(input, name)
+		if err != nil {
+			return nil, err
+		}
+		return SubmatchSplit(hdr, RS, LP, r)
+	},
+	"parseColumns": func(RS, W, header, input string, name ...string) (interface{}, error) {
+		if RS == "" {
+			RS = *RecordSeparator
+		}
+		hdr := splitHeader(header)
+		r, err := named(input, name)
+		if err != nil {
+			return nil, err
+		}
+		return Columns(hdr, RS, W, r)
 	},
-	"parse": func(RS, FS, header, input string) (interface{}, error) {
+	"parse": func(RS, FS, header, input string, name ...string) (interface{}, error) {
 		if RS == "" {
 			RS = *RecordSeparator
 		}
@@ -105,7 +133,11 @@ var funcs = map[string]interface{}{
 			FS = *FieldSeparator
 		}
 		hdr := splitHeader(header)
-		return Split(hdr, RS, FS, rdr(input))
+		r, err := named(input, name)
+		if err != nil {
+			return nil, err
+		}
+		return Split(hdr, RS, FS, r)
 	},
 	"quoteCSV": func(s string) string {
 		hasQuote := strings.Index(s, `"`) > 0
@@ -175,11 +207,110 @@ var funcs = map[string]interface{}{
 		}
 		return r.Split(src, -1), nil
 	},
+	"submatch": func(pattern, src string) ([]string, error) {
+		r, err := cmpl(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return submatches(r, []byte(src)), nil
+	},
+	"submatchAll": func(pattern, src string) ([][]string, error) {
+		r, err := cmpl(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return r.FindAllStringSubmatch(src, -1), nil
+	},
+	"namedSubmatch": func(pattern, src string) (map[string]string, error) {
+		r, err := cmpl(pattern)
+		if err != nil {
+			return nil, err
+		}
+		sms := submatches(r, []byte(src))
+		if sms == nil {
+			return nil, nil
+		}
+		names := map[string]int{}
+		for i, name := range r.SubexpNames() {
+			if name != "" {
+				names[name] = i
+			}
+		}
+		return nameRow(names, sms), nil
+	},
+
+	"add":   add,
+	"sub":   sub,
+	"mul":   mul,
+	"div":   div,
+	"mod":   mod,
+	"min":   minOf,
+	"max":   maxOf,
+	"abs":   abs,
+	"round": round,
+	"floor": floor,
+	"ceil":  ceil,
+
+	"sum":        sum,
+	"mean":       mean,
+	"median":     median,
+	"percentile": percentile,
+	"stddev":     stddev,
+	"count":      count,
+
+	"sort":   sortList,
+	"sortBy": sortBy,
+
+	"groupBy": groupBy,
+	"countBy": countBy,
+	"uniq": func(list interface{}) (interface{}, error) {
+		return uniqBy(list)
+	},
+	"uniqBy": func(list, key interface{}) (interface{}, error) {
+		return uniqBy(list, key)
+	},
+
+	"where":  where,
+	"reject": reject,
+	"pluck":  pluck,
+	"map":    project,
+
+	//set on maps is with the store functions in store.go
+	"dict":     dict,
+	"list":     list,
+	"append":   appendList,
+	"prepend":  prependList,
+	"unset":    unsetKey,
+	"merge":    merge,
+	"keys":     keys,
+	"values":   values,
+	"hasKey":   hasKey,
+	"contains": contains,
+
+	"now":           time.Now,
+	"parseTime":     parseTime,
+	"formatTime":    formatTime,
+	"unix":          unix,
+	"fromUnix":      fromUnix,
+	"parseDuration": time.ParseDuration,
+	"duration":      duration,
+	"seconds":       seconds,
+	"addTime":       addTime,
+	"subTime":       subTime,
+	"inZone":        inZone,
 
 	"env": os.Getenv,
 
-	"exec": func(name string, args ...string) string {
-		return run(exec.Command(name, args...))
+	"exec": func(name string, args ...string) (string, error) {
+		return output(exec.Command(name, args...))
+	},
+	"execResult": func(name string, args ...string) (*result, error) {
+		//failures are reported by the result, unless there is no result
+		r, err := run(exec.Command(name, args...), nil)
+		if r == nil {
+			return nil, err
+		}
+		return r, nil
 	},
 	"pipe": func(name string, args ...string) (string, error) {
 		if len(args) == 0 {
@@ -190,6 +321,8 @@ var funcs = map[string]interface{}{
 		args = args[:last]
 		cmd := exec.Command(name, args...)
 		cmd.Stdin = strings.NewReader(input)
-		return run(cmd), nil
+		return output(cmd)
 	},
+	"sh":     sh,
+	"shPipe": shPipe,
 }
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func execute(t *testing.T, src string, data interface{}) string {
+	tmpl, err := Parse(false, src, "{{", "}}", funcs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&b, "", data); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestSubmatch(t *testing.T) {
+	var tests = []struct {
+		src, out string
+	}{
+		{`{{index (submatch "(\\w+)@(\\w+)" .) 2}}`, "b"},
+		{`{{with submatch "x(y)" .}}{{.}}{{else}}none{{end}}`, "none"},
+		{`{{range submatchAll "(\\w+)@" .}}{{index . 1}};{{end}}`, "a;c;"},
+		{`{{with namedSubmatch "(?P<user>\\w+)@(\\w+)" .}}{{.user}} {{len .}}{{end}}`, "a 1"},
+		{`{{with namedSubmatch "(?P<user>z)" .}}{{.}}{{else}}none{{end}}`, "none"},
+	}
+	for i, v := range tests {
+		if out := execute(t, v.src, "a@b c@d"); out != v.out {
+			t.Errorf("test case %d: expected %q, got %q", i, v.out, out)
+		}
+	}
+}
+
+func TestReplaceWith(t *testing.T) {
+	const src = `{{define "up"}}{{upper (index . 1)}}{{end}}{{replaceWith "\\$(\\w+)" "up" .}}`
+	if out := execute(t, src, "a $b c $d"); out != "a B c D" {
+		t.Errorf("expected %q, got %q", "a B c D", out)
+	}
+
+	tmpl, err := Parse(true, `{{define "b"}}<b>{{index . 0}}</b>{{end}}{{replaceWith "x" "b" .}}`, "{{", "}}", funcs)
+	failIf(t, 0, err)
+	var b bytes.Buffer
+	failIf(t, 0, tmpl.ExecuteTemplate(&b, "", "a&x<"))
+	if out := b.String(); out != "a&amp;<b>x</b>&lt;" {
+		t.Errorf("expected %q, got %q", "a&amp;<b>x</b>&lt;", out)
+	}
+
+	tmpl, err = Parse(false, `{{replaceWith "x" "missing" .}}`, "{{", "}}", funcs)
+	failIf(t, 0, err)
+	if err := tmpl.ExecuteTemplate(&bytes.Buffer{}, "", "x"); err == nil {
+		t.Error("expected error for missing template")
+	}
+}
+
+func TestParseShebang(t *testing.T) {
+	dir := t.TempDir()
+	file := dir + "/script"
+	failIf(t, 0, os.WriteFile(file, []byte("#!/usr/bin/env txt\nhello"), 0700))
+	tmpl, err := Parse(false, "", "{{", "}}", funcs, file)
+	failIf(t, 0, err)
+	var b bytes.Buffer
+	failIf(t, 0, tmpl.ExecuteTemplate(&b, "script", nil))
+	if out := b.String(); out != "hello" {
+		t.Errorf("expected %q, got %q", "hello", out)
+	}
+}
+
+func TestExecResult(t *testing.T) {
+	const src = `{{with execResult "sh" "-c" "echo hi; echo no >&2; exit 2"}}{{.ExitCode}} {{.Stdout | trim}} {{.Stderr | trim}}{{end}}`
+	*Strict = true
+	defer func() { *Strict = false }()
+	if out := execute(t, src, nil); out != "2 hi no" {
+		t.Errorf("expected %q, got %q", "2 hi no", out)
+	}
+
+	tmpl, err := Parse(false, `{{execResult "/nonexistent/command"}}`, "{{", "}}", funcs)
+	failIf(t, 0, err)
+	if err := tmpl.ExecuteTemplate(&bytes.Buffer{}, "", nil); err == nil {
+		t.Error("expected error for missing command")
+	}
+}
+
+func TestParseFilename(t *testing.T) {
+	const src = `{{range parse "" "" "" "a\nb" "app.log"}}{{.FILENAME}}:{{.NR}} {{end}}` +
+		`{{range parseLine "" "(b)" "" "a\nb" "x.log"}}{{.FILENAME}}:{{.NR}} {{end}}` +
+		`{{range parse "" "" "" "a"}}[{{.FILENAME}}]{{end}}`
+	if out := execute(t, src, nil); out != "app.log:1 app.log:2 x.log:2 []" {
+		t.Errorf("expected %q, got %q", "app.log:1 app.log:2 x.log:2 []", out)
+	}
+}
@@ -2,4 +2,8 @@ module github.com/jimmyfrasche/txt
 
 go 1.21.0
 
-require github.com/jimmyfrasche/invert v1.0.0
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/jimmyfrasche/invert v1.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
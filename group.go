@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type group struct {
+	Key     interface{}
+	Members interface{}
+}
+
+type tally struct {
+	Key   interface{}
+	Count int
+}
+
+func groupKey(v interface{}) interface{} {
+	//a comparable stand-in for v so that equal items are equal keys
+	switch x := v.(type) {
+	case *record:
+		return x.Line
+	case *row:
+		return strings.Join(x.Fields, "\x00")
+	}
+	if v != nil && reflect.TypeOf(v).Comparable() {
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+func partition(list interface{}, key []interface{}) (keys []interface{}, members [][]int, err error) {
+	//the distinct values of the key, or the items, in order of first appearance,
+	//and the indicies of the items with each
+	vs, err := fieldOf(list, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	seen := map[interface{}]int{}
+	for i, v := range vs {
+		k := groupKey(v)
+		g, ok := seen[k]
+		if !ok {
+			g = len(keys)
+			seen[k] = g
+			keys = append(keys, v)
+			members = append(members, nil)
+		}
+		members[g] = append(members[g], i)
+	}
+	return keys, members, nil
+}
+
+func groupBy(list, key interface{}) ([]*group, error) {
+	keys, members, err := partition(list, []interface{}{key})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*group, len(keys))
+	for i, k := range keys {
+		out[i] = &group{
+			Key:     k,
+			Members: subset(list, members[i]),
+		}
+	}
+	return out, nil
+}
+
+func countBy(list, key interface{}) ([]*tally, error) {
+	keys, members, err := partition(list, []interface{}{key})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*tally, len(keys))
+	for i, k := range keys {
+		out[i] = &tally{
+			Key:   k,
+			Count: len(members[i]),
+		}
+	}
+	return out, nil
+}
+
+func uniqBy(list interface{}, key ...interface{}) (interface{}, error) {
+	_, members, err := partition(list, key)
+	if err != nil {
+		return nil, err
+	}
+	firsts := make([]int, len(members))
+	for i, m := range members {
+		firsts[i] = m[0]
+	}
+	if len(firsts) == 0 {
+		return list, nil
+	}
+	return subset(list, firsts), nil
+}
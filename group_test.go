@@ -0,0 +1,63 @@
+package main
+
+import "testing"
+
+var groupCorpus = "a 200\nb 404\na 500\nc 200\na 200\n"
+
+func TestGroupBy(t *testing.T) {
+	ret, err := Split(splitHeader("host,status"), RS, FS, rdr(groupCorpus))
+	failIf(t, 0, err)
+	gs, err := groupBy(ret, "host")
+	failIf(t, 0, err)
+
+	var keys []string
+	for _, g := range gs {
+		keys = append(keys, g.Key.(string))
+	}
+	failIf(t, 0, listEquals(0, []string{"a", "b", "c"}, keys))
+	failIf(t, 0, listMapEquals([]map[string]string{
+		{"host": "a", "status": "200"},
+		{"host": "a", "status": "500"},
+		{"host": "a", "status": "200"},
+	}, gs[0].Members.([]map[string]string)))
+}
+
+func TestCountBy(t *testing.T) {
+	ret, err := Split(nil, RS, FS, rdr(groupCorpus))
+	failIf(t, 0, err)
+	ts, err := countBy(ret, 1)
+	failIf(t, 0, err)
+	if len(ts) != 3 || ts[0].Key != "200" || ts[0].Count != 3 || ts[2].Count != 1 {
+		t.Errorf("wrong counts: %v %v %v", ts[0], ts[1], ts[2])
+	}
+
+	sorted, err := sortBy(ts, "Count:n")
+	failIf(t, 0, err)
+	if c := sorted.([]*tally)[2].Count; c != 3 {
+		t.Errorf("sorting by Count: %d ≠ 3", c)
+	}
+}
+
+func TestUniq(t *testing.T) {
+	ret, err := Split(nil, RS, FS, rdr(groupCorpus))
+	failIf(t, 0, err)
+	u, err := uniqBy(ret)
+	failIf(t, 0, err)
+	if n := len(u.([]*record)); n != 4 {
+		t.Errorf("uniq: %d records ≠ 4", n)
+	}
+
+	u, err = uniqBy(ret, 0)
+	failIf(t, 0, err)
+	recs, err := fields(u)
+	failIf(t, 0, err)
+	failIf(t, 0, listListEquals([][]string{
+		{"a", "200"},
+		{"b", "404"},
+		{"c", "200"},
+	}, recs))
+
+	u, err = uniqBy([]string{"x", "y", "x"})
+	failIf(t, 0, err)
+	failIf(t, 0, listEquals(0, []string{"x", "y"}, u.([]string)))
+}
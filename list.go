@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+func items(list interface{}) ([]interface{}, error) {
+	if is, ok := list.([]interface{}); ok {
+		return is, nil
+	}
+	v := reflect.ValueOf(list)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+	case reflect.Invalid:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("can't use type %s as list", v.Type())
+	}
+	out := make([]interface{}, v.Len())
+	for i := range out {
+		out[i] = v.Index(i).Interface()
+	}
+	return out, nil
+}
+
+func field(item, key interface{}) (interface{}, error) {
+	//key is a name for maps and rows or an index for records, rows, and lists
+	name, byName := key.(string)
+	switch it := item.(type) {
+	case *record:
+		if byName {
+			//so that indicies can be given in strings, like key specs
+			i, err := strconv.Atoi(name)
+			if err != nil {
+				return nil, fmt.Errorf("can't use %q as index of record", name)
+			}
+			return it.F(i), nil
+		}
+		i, err := index(key)
+		if err != nil {
+			return nil, err
+		}
+		return it.F(i), nil
+	case *row:
+		if byName {
+			return it.Get(name), nil
+		}
+		i, err := index(key)
+		if err != nil {
+			return nil, err
+		}
+		return it.F(i), nil
+	case map[string]string:
+		if !byName {
+			return nil, fmt.Errorf("can't use %v as map key", key)
+		}
+		return it[name], nil
+	case map[string]interface{}:
+		if !byName {
+			return nil, fmt.Errorf("can't use %v as map key", key)
+		}
+		return it[name], nil
+	}
+
+	v := reflect.ValueOf(item)
+	switch v.Kind() {
+	case reflect.Map:
+		k := reflect.ValueOf(key)
+		if !k.IsValid() || !k.Type().AssignableTo(v.Type().Key()) {
+			return nil, fmt.Errorf("can't use %v as key of %s", key, v.Type())
+		}
+		if e := v.MapIndex(k); e.IsValid() {
+			return e.Interface(), nil
+		}
+		return nil, nil
+	case reflect.Slice, reflect.Array:
+		i, err := index(key)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			i += v.Len()
+		}
+		if i < 0 || i >= v.Len() {
+			return nil, nil
+		}
+		return v.Index(i).Interface(), nil
+	case reflect.Ptr:
+		if v.Elem().Kind() == reflect.Struct {
+			return structField(v.Elem(), key)
+		}
+	case reflect.Struct:
+		return structField(v, key)
+	case reflect.Invalid:
+		return nil, fmt.Errorf("can't get field %v of nothing", key)
+	}
+	return nil, fmt.Errorf("can't get field %v of type %s", key, v.Type())
+}
+
+func structField(v reflect.Value, key interface{}) (interface{}, error) {
+	name, ok := key.(string)
+	if !ok {
+		return nil, fmt.Errorf("can't use %v as field name of %s", key, v.Type())
+	}
+	f := v.FieldByName(name)
+	if !f.IsValid() || !f.CanInterface() {
+		return nil, fmt.Errorf("%s has no field %s", v.Type(), name)
+	}
+	return f.Interface(), nil
+}
+
+func fieldOf(list interface{}, key []interface{}) ([]interface{}, error) {
+	//the items of list, or the field key of each, if there is a key
+	is, err := items(list)
+	if err != nil || len(key) == 0 {
+		return is, err
+	}
+	if len(key) > 1 {
+		return nil, fmt.Errorf("expected at most one field, given %d", len(key))
+	}
+	out := make([]interface{}, 0, len(is))
+	for _, it := range is {
+		f, err := field(it, key[0])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+func subset(list interface{}, idx []int) interface{} {
+	//the items of list at idx in a list of the same type, so that
+	//records stay records and rows keep the Header method
+	v := reflect.ValueOf(list)
+	typ := v.Type()
+	if v.Kind() == reflect.Array {
+		typ = reflect.SliceOf(typ.Elem())
+	}
+	out := reflect.MakeSlice(typ, 0, len(idx))
+	for _, i := range idx {
+		out = reflect.Append(out, v.Index(i))
+	}
+	return out.Interface()
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type number struct {
+	//the value is i if isInt, otherwise f
+	i     int
+	f     float64
+	isInt bool
+}
+
+func (n number) float() float64 {
+	if n.isInt {
+		return float64(n.i)
+	}
+	return n.f
+}
+
+func (n number) value() interface{} {
+	if n.isInt {
+		return n.i
+	}
+	return n.f
+}
+
+func toNumber(v interface{}) (number, error) {
+	switch x := reflect.ValueOf(v); x.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := overflow(x.Int())
+		return number{i: i, isInt: true}, err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := x.Uint()
+		if u > math.MaxInt64 {
+			return number{}, fmt.Errorf("%d is too large", u)
+		}
+		i, err := overflow(int64(u))
+		return number{i: i, isInt: true}, err
+	case reflect.Float32, reflect.Float64:
+		return number{f: x.Float()}, nil
+	case reflect.String:
+		s := strings.TrimSpace(x.String())
+		if i, err := strconv.Atoi(s); err == nil {
+			return number{i: i, isInt: true}, nil
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return number{f: f}, nil
+		}
+		return number{}, fmt.Errorf("%q is not a number", x.String())
+	case reflect.Invalid:
+		return number{}, errors.New("missing value is not a number")
+	default:
+		return number{}, fmt.Errorf("%v of type %s is not a number", v, x.Type())
+	}
+}
+
+func toNumbers(vs []interface{}) ([]number, error) {
+	ns := make([]number, 0, len(vs))
+	for _, v := range vs {
+		n, err := toNumber(v)
+		if err != nil {
+			return nil, err
+		}
+		ns = append(ns, n)
+	}
+	return ns, nil
+}
+
+func fold(name string, vs []interface{}, i func(a, b int) int, f func(a, b float64) float64) (interface{}, error) {
+	//ints stay ints until a float is encountered
+	if len(vs) == 0 {
+		return nil, fmt.Errorf("%s requires at least one argument", name)
+	}
+	ns, err := toNumbers(vs)
+	if err != nil {
+		return nil, err
+	}
+	acc := ns[0]
+	for _, n := range ns[1:] {
+		if acc.isInt && n.isInt {
+			acc.i = i(acc.i, n.i)
+		} else {
+			acc = number{f: f(acc.float(), n.float())}
+		}
+	}
+	return acc.value(), nil
+}
+
+func add(vs ...interface{}) (interface{}, error) {
+	return fold("add", vs,
+		func(a, b int) int { return a + b },
+		func(a, b float64) float64 { return a + b })
+}
+
+func sub(a, b interface{}) (interface{}, error) {
+	return fold("sub", []interface{}{a, b},
+		func(a, b int) int { return a - b },
+		func(a, b float64) float64 { return a - b })
+}
+
+func mul(vs ...interface{}) (interface{}, error) {
+	return fold("mul", vs,
+		func(a, b int) int { return a * b },
+		func(a, b float64) float64 { return a * b })
+}
+
+func div(a, b interface{}) (interface{}, error) {
+	ns, err := toNumbers([]interface{}{a, b})
+	if err != nil {
+		return nil, err
+	}
+	if ns[1].float() == 0 {
+		return nil, errors.New("division by zero")
+	}
+	return ns[0].float() / ns[1].float(), nil
+}
+
+func mod(a, b interface{}) (interface{}, error) {
+	ns, err := toNumbers([]interface{}{a, b})
+	if err != nil {
+		return nil, err
+	}
+	if ns[1].float() == 0 {
+		return nil, errors.New("division by zero")
+	}
+	if ns[0].isInt && ns[1].isInt {
+		return ns[0].i % ns[1].i, nil
+	}
+	return math.Mod(ns[0].float(), ns[1].float()), nil
+}
+
+func minimum(vs ...interface{}) (interface{}, error) {
+	return fold("min", vs,
+		func(a, b int) int {
+			if b < a {
+				return b
+			}
+			return a
+		},
+		math.Min)
+}
+
+func maximum(vs ...interface{}) (interface{}, error) {
+	return fold("max", vs,
+		func(a, b int) int {
+			if b > a {
+				return b
+			}
+			return a
+		},
+		math.Max)
+}
+
+func abs(v interface{}) (interface{}, error) {
+	n, err := toNumber(v)
+	if err != nil {
+		return nil, err
+	}
+	if n.isInt {
+		if n.i < 0 {
+			return -n.i, nil
+		}
+		return n.i, nil
+	}
+	return math.Abs(n.f), nil
+}
+
+func toInt(f float64) (int, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("%g cannot be converted to an integer", f)
+	}
+	return overflow(int64(f))
+}
+
+func round(v interface{}, places ...interface{}) (interface{}, error) {
+	n, err := toNumber(v)
+	if err != nil {
+		return nil, err
+	}
+	switch len(places) {
+	case 0:
+		return toInt(math.Round(n.float()))
+	case 1:
+		p, err := toNumber(places[0])
+		if err != nil {
+			return nil, err
+		}
+		if !p.isInt {
+			return nil, fmt.Errorf("round: %g places is not an integer", p.f)
+		}
+		scale := math.Pow10(p.i)
+		return math.Round(n.float()*scale) / scale, nil
+	default:
+		return nil, fmt.Errorf("round takes 1 or 2 arguments, given %d", len(places)+1)
+	}
+}
+
+func floor(v interface{}) (interface{}, error) {
+	n, err := toNumber(v)
+	if err != nil {
+		return nil, err
+	}
+	return toInt(math.Floor(n.float()))
+}
+
+func ceil(v interface{}) (interface{}, error) {
+	n, err := toNumber(v)
+	if err != nil {
+		return nil, err
+	}
+	return toInt(math.Ceil(n.float()))
+}
@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+var mathTests = []struct {
+	name string
+	f    func() (interface{}, error)
+	out  interface{}
+}{
+	{"add ints", func() (interface{}, error) { return add(1, "2", int64(3)) }, 6},
+	{"add floats", func() (interface{}, error) { return add(1, " 2.5 ") }, 3.5},
+	{"sub", func() (interface{}, error) { return sub("10", 4) }, 6},
+	{"mul", func() (interface{}, error) { return mul(2, 3, 4) }, 24},
+	{"div", func() (interface{}, error) { return div(50, "200") }, 0.25},
+	{"mod ints", func() (interface{}, error) { return mod(7, 3) }, 1},
+	{"mod floats", func() (interface{}, error) { return mod(7.5, 2) }, 1.5},
+	{"min", func() (interface{}, error) { return minimum(3, "1", 2) }, 1},
+	{"max", func() (interface{}, error) { return maximum(3, 4.5, 2) }, 4.5},
+	{"abs", func() (interface{}, error) { return abs("-3") }, 3},
+	{"round", func() (interface{}, error) { return round(2.5) }, 3},
+	{"round places", func() (interface{}, error) { return round(2.345, 2) }, 2.35},
+	{"floor", func() (interface{}, error) { return floor("-1.5") }, -2},
+	{"ceil", func() (interface{}, error) { return ceil(1.2) }, 2},
+}
+
+func TestMath(t *testing.T) {
+	for _, v := range mathTests {
+		out, err := v.f()
+		if err != nil {
+			t.Errorf("%s: %s", v.name, err)
+		} else if out != v.out {
+			t.Errorf("%s: %#v ≠ %#v", v.name, out, v.out)
+		}
+	}
+}
+
+func TestMathErrors(t *testing.T) {
+	if _, err := add(1, "x"); err == nil || err.Error() != `"x" is not a number` {
+		t.Errorf("wrong error returned: %v", err)
+	}
+	if _, err := div(1, "0"); err == nil {
+		t.Error("expected error for division by zero")
+	}
+	if _, err := add(); err == nil {
+		t.Error("expected error for no arguments")
+	}
+	if _, err := add(true); err == nil {
+		t.Error("expected error for bool argument")
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	htmltemplate "html/template"
 	"io/ioutil"
 	"path/filepath"
 )
@@ -10,6 +11,23 @@ var shebang = []byte("#!")
 
 func Parse(usehtml bool, e, left, right string, fs map[string]interface{}, files ...string) (t template, err error) {
 	new := htmlOrText[usehtml]
+
+	//every template shares the same store and may call the others
+	var rw interface{} = func(pattern, name, src string) (string, error) {
+		return replaceWith(t, pattern, name, src, nil)
+	}
+	if usehtml {
+		//the output of the template called is already escaped,
+		//so escape the rest and keep it from being escaped again
+		rw = func(pattern, name, src string) (htmltemplate.HTML, error) {
+			s, err := replaceWith(t, pattern, name, src, htmltemplate.HTMLEscapeString)
+			return htmltemplate.HTML(s), err
+		}
+	}
+	fs = bind(fs, newStore().funcs(), map[string]interface{}{
+		"replaceWith": rw,
+	})
+
 	if e != "" {
 		t = new("", left, right, fs)
 		if t, err = t.Parse(e); err != nil {
@@ -23,7 +41,7 @@ func Parse(usehtml bool, e, left, right string, fs map[string]interface{}, files
 			return nil, err
 		}
 
-		if len(b) > 2 && bytes.Equal(b[:3], shebang) {
+		if bytes.HasPrefix(b, shebang) {
 			if i := bytes.IndexAny(b, "\n"); i > 0 && len(b) != i {
 				b = b[i+1:]
 			} else {
@@ -47,3 +65,14 @@ func Parse(usehtml bool, e, left, right string, fs map[string]interface{}, files
 	}
 	return
 }
+
+func bind(fss ...map[string]interface{}) map[string]interface{} {
+	//a copy of the funcs, with later funcs replacing earlier ones
+	out := map[string]interface{}{}
+	for _, fs := range fss {
+		for k, v := range fs {
+			out[k] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+func replaceWith(t template, pattern, name, src string, escape func(string) string) (string, error) {
+	//each match is replaced by the output of the template name executed
+	//with the submatches of the match as dot.
+	//If escape is not nil, it is applied to the text between matches.
+	r, err := cmpl(pattern)
+	if err != nil {
+		return "", err
+	}
+	if t == nil || t.Lookup(name) == nil {
+		return "", fmt.Errorf("no template named %q", name)
+	}
+	var out strings.Builder
+	var b bytes.Buffer
+	last := 0
+	between := func(s string) {
+		if escape != nil {
+			s = escape(s)
+		}
+		out.WriteString(s)
+	}
+	for _, loc := range r.FindAllStringSubmatchIndex(src, -1) {
+		sms := make([]string, len(loc)/2)
+		for i := range sms {
+			if loc[2*i] >= 0 {
+				sms[i] = src[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		b.Reset()
+		if err := t.ExecuteTemplate(&b, name, sms); err != nil {
+			return "", err
+		}
+		between(src[last:loc[0]])
+		out.Write(b.Bytes())
+		last = loc[1]
+	}
+	between(src[last:])
+	return out.String(), nil
+}
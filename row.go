@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+type row struct {
+	Header []string
+	Fields []string
+	values []interface{} //if the header has types
+	index  map[string]int
+}
+
+func (r *row) Get(name string) interface{} {
+	i, ok := r.index[name]
+	if !ok || i >= len(r.Fields) {
+		return ""
+	}
+	if r.values != nil {
+		return r.values[i]
+	}
+	return r.Fields[i]
+}
+
+func (r *row) F(n int) string {
+	ln := len(r.Fields)
+	if n < 0 {
+		n = ln + n
+	}
+	if n < 0 || n >= ln {
+		return ""
+	}
+	return r.Fields[n]
+}
+
+func (r *row) MarshalJSON() ([]byte, error) {
+	//an object with the keys in the order of the header
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, h := range r.Header {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, err := json.Marshal(h)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(r.Get(h))
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+type table []*row
+
+func (t table) Header() []string {
+	if len(t) == 0 {
+		return nil
+	}
+	return t[0].Header
+}
+
+type namer struct {
+	//builds the rows of input with a header,
+	//as maps or, if -ordered, as rows in the order of the header.
+	names  map[string]int
+	types  map[string]conversion
+	header []string
+	index  map[string]int
+	maps   []map[string]string
+	typed  []map[string]interface{}
+	table  table
+}
+
+func newNamer(names map[string]int, types map[string]conversion) *namer {
+	header := make([]string, 0, len(names))
+	for name := range names {
+		header = append(header, name)
+	}
+	sort.Slice(header, func(i, j int) bool {
+		return names[header[i]] < names[header[j]]
+	})
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[name] = i
+	}
+	return &namer{
+		names:  names,
+		types:  types,
+		header: header,
+		index:  index,
+		maps:   []map[string]string{},
+		typed:  []map[string]interface{}{},
+		table:  table{},
+	}
+}
+
+func (n *namer) convert(rec int, name, field string) (interface{}, error) {
+	c, ok := n.types[name]
+	if !ok {
+		return field, nil
+	}
+	v, err := c(field)
+	if err != nil {
+		return nil, fmt.Errorf("%d: column %q: %s", rec, name, err)
+	}
+	return v, nil
+}
+
+func (n *namer) row(rec int, fields []string) (interface{}, error) {
+	//rec is the number of the record in the input, from 0, for errors
+	if *Ordered {
+		r := &row{
+			Header: n.header,
+			Fields: make([]string, len(n.header)),
+			index:  n.index,
+		}
+		for i, name := range n.header {
+			if j := n.names[name]; j < len(fields) {
+				r.Fields[i] = fields[j]
+			}
+		}
+		if n.types != nil {
+			r.values = make([]interface{}, len(r.Fields))
+			for i, name := range n.header {
+				v, err := n.convert(rec, name, r.Fields[i])
+				if err != nil {
+					return nil, err
+				}
+				r.values[i] = v
+			}
+		}
+		return r, nil
+	}
+
+	m := nameRow(n.names, fields)
+	if n.types == nil {
+		return m, nil
+	}
+	typed := make(map[string]interface{}, len(m))
+	for name, field := range m {
+		v, err := n.convert(rec, name, field)
+		if err != nil {
+			return nil, err
+		}
+		typed[name] = v
+	}
+	return typed, nil
+}
+
+func (n *namer) add(rec int, fields []string) error {
+	r, err := n.row(rec, fields)
+	if err != nil {
+		return err
+	}
+	switch r := r.(type) {
+	case *row:
+		n.table = append(n.table, r)
+	case map[string]string:
+		n.maps = append(n.maps, r)
+	case map[string]interface{}:
+		n.typed = append(n.typed, r)
+	}
+	return nil
+}
+
+func (n *namer) rows() interface{} {
+	if *Ordered {
+		return n.table
+	}
+	if n.types != nil {
+		return n.typed
+	}
+	return n.maps
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOrderedCSV(t *testing.T) {
+	*Ordered = true
+	defer func() { *Ordered = false }()
+
+	ret, err := CSV(nil, rdr("c,a,b\n1,2,3\n4,5,6\n"))
+	failIf(t, 0, err)
+	tbl, ok := ret.(table)
+	if !ok {
+		t.Fatalf("wrong return type, expected table, got %T", ret)
+	}
+	failIf(t, 0, listEquals(0, []string{"c", "a", "b"}, tbl.Header()))
+	failIf(t, 0, listEquals(1, []string{"4", "5", "6"}, tbl[1].Fields))
+	if v := tbl[0].Get("a"); v != "2" {
+		t.Errorf("Get: %#v ≠ %#v", v, "2")
+	}
+	if v := tbl[0].F(-1); v != "3" {
+		t.Errorf("F: %#v ≠ %#v", v, "3")
+	}
+
+	bs, err := json.Marshal(tbl[0])
+	failIf(t, 0, err)
+	if s := string(bs); s != `{"c":"1","a":"2","b":"3"}` {
+		t.Errorf("wrong JSON: %s", s)
+	}
+}
+
+func TestOrderedSubmatchNames(t *testing.T) {
+	*Ordered = true
+	defer func() { *Ordered = false }()
+
+	ret, err := SubmatchSplit(nil, RS, `(?P<z>\w+)=(?P<a>\w+)`, rdr("k=v\n"))
+	failIf(t, 0, err)
+	tbl := ret.(table)
+	failIf(t, 0, listEquals(0, []string{"z", "a"}, tbl.Header()))
+	failIf(t, 0, listEquals(0, []string{"k", "v"}, tbl[0].Fields))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var unsafe = map[string]bool{
+	//the functions removed by -safe, as they run commands or read outside of stdin
+	"exec":         true,
+	"execResult":   true,
+	"pipe":         true,
+	"sh":           true,
+	"shPipe":       true,
+	"read":         true,
+	"env":          true,
+	"parse":        true,
+	"parseCSV":     true,
+	"parseColumns": true,
+	"parseJSON":    true,
+	"parseJSONL":   true,
+	"parseLine":    true,
+	"parseTOML":    true,
+	"parseXML":     true,
+	"parseYAML":    true,
+}
+
+func restrict(fs map[string]interface{}, allow []string) (map[string]interface{}, error) {
+	allowed := map[string]bool{}
+	for _, name := range allow {
+		if !unsafe[name] {
+			return nil, fmt.Errorf("%q is not a function disabled by -safe", name)
+		}
+		allowed[name] = true
+	}
+	out := make(map[string]interface{}, len(fs))
+	for name, f := range fs {
+		if !unsafe[name] || allowed[name] {
+			out[name] = f
+		}
+	}
+	return out, nil
+}
+
+var undefined = regexp.MustCompile(`function "([^"]+)" not defined`)
+
+func disallowed(err error) error {
+	//explain why a function removed by restrict is not defined
+	if m := undefined.FindStringSubmatch(err.Error()); m != nil && unsafe[m[1]] {
+		return fmt.Errorf("%s: %s is disabled by -safe, enable it with -allow=%s", err, m[1], m[1])
+	}
+	return err
+}
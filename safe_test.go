@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRestrict(t *testing.T) {
+	fs, err := restrict(funcs, []string{"env"})
+	failIf(t, 0, err)
+	if _, ok := fs["exec"]; ok {
+		t.Error("expected exec to be removed")
+	}
+	if _, ok := fs["env"]; !ok {
+		t.Error("expected env to be allowed")
+	}
+	if _, ok := fs["upper"]; !ok {
+		t.Error("expected upper to be kept")
+	}
+
+	_, err = Parse(false, `{{exec "ls"}}`, "{{", "}}", fs)
+	if err == nil {
+		t.Fatal("expected parse error")
+	}
+	if err = disallowed(err); !strings.Contains(err.Error(), "-allow=exec") {
+		t.Errorf("expected error to explain -allow, got %s", err)
+	}
+
+	if _, err := restrict(funcs, []string{"upper"}); err == nil {
+		t.Error("expected error allowing a safe function")
+	}
+}
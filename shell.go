@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+func shell(cmdline string, options interface{}) (*exec.Cmd, error) {
+	//cmdline run by /bin/sh -c, configured by the options env, dir, and input
+	c := exec.Command("/bin/sh", "-c", cmdline)
+	opts, err := mapOf(options)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range opts {
+		switch k {
+		case "env":
+			env, err := mapOf(v)
+			if err != nil {
+				return nil, fmt.Errorf("env: %s", err)
+			}
+			names := make([]string, 0, len(env))
+			for name := range env {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			//added to, and overriding, the environment of txt(1)
+			c.Env = os.Environ()
+			for _, name := range names {
+				c.Env = append(c.Env, name+"="+text(env[name]))
+			}
+		case "dir":
+			c.Dir = text(v)
+		case "input":
+			c.Stdin = rdr(text(v))
+		default:
+			return nil, fmt.Errorf("unknown sh option %q", k)
+		}
+	}
+	return c, nil
+}
+
+func sh(cmdline string, options ...interface{}) (string, error) {
+	if len(options) > 1 {
+		return "", fmt.Errorf("sh takes 1 or 2 arguments, given %d", len(options)+1)
+	}
+	var opts interface{}
+	if len(options) == 1 {
+		opts = options[0]
+	}
+	c, err := shell(cmdline, opts)
+	if err != nil {
+		return "", err
+	}
+	return output(c)
+}
+
+func shPipe(cmdline string, args ...interface{}) (string, error) {
+	var opts interface{}
+	switch len(args) {
+	case 1:
+	case 2:
+		opts = args[0]
+	default:
+		return "", fmt.Errorf("shPipe takes 2 or 3 arguments, given %d", len(args)+1)
+	}
+	c, err := shell(cmdline, opts)
+	if err != nil {
+		return "", err
+	}
+	c.Stdin = strings.NewReader(text(args[len(args)-1]))
+	return output(c)
+}
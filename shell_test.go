@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSh(t *testing.T) {
+	out, err := sh(`echo "$GREETING" | tr a-z A-Z; pwd`, map[string]interface{}{
+		"env": map[string]string{"GREETING": "hi"},
+		"dir": "/",
+	})
+	failIf(t, 0, err)
+	if out != "HI\n/\n" {
+		t.Errorf("expected %q, got %q", "HI\n/\n", out)
+	}
+
+	out, err = shPipe("sort | head -n 1", "b\na\n")
+	failIf(t, 0, err)
+	if out != "a\n" {
+		t.Errorf("expected %q, got %q", "a\n", out)
+	}
+
+	out, err = sh("cat", map[string]interface{}{"input": "x"})
+	failIf(t, 0, err)
+	if out != "x" {
+		t.Errorf("expected %q, got %q", "x", out)
+	}
+
+	if _, err := sh("true", map[string]interface{}{"bogus": 1}); err == nil {
+		t.Error("expected error for unknown option")
+	}
+
+	*Strict = true
+	defer func() { *Strict = false }()
+	if _, err := sh("echo oops >&2; exit 2"); err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Errorf("expected error with stderr, got %v", err)
+	}
+}
@@ -1,19 +1,33 @@
 package main
 
 import (
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"regexp"
+	"strings"
 
+	"github.com/BurntSushi/toml"
 	"github.com/jimmyfrasche/invert"
+	"gopkg.in/yaml.v3"
 )
 
 type record struct {
-	Fields []string
-	Line   string
+	Fields   []string
+	Line     string
+	NR       int
+	FILENAME string
+	Start    int
+	End      int
+}
+
+func (r *record) NF() int {
+	return len(r.Fields)
 }
 
 func (r *record) F(n int) string {
@@ -31,19 +45,43 @@ func (r *record) String() string {
 	return r.Line
 }
 
-func SubmatchSplit(header []string, RS, LinePattern string, Stdin io.Reader) (ret interface{}, err error) {
-	rs, err := cmpl(RS) //might as well add these to the cache
-	if err != nil {
-		return
+func splitFields(fs *regexp.Regexp, s []byte) []string {
+	is := fs.FindAllIndex(s, -1)
+	is = invert.Indicies(is, len(s))
+
+	row := make([]string, 0, len(is))
+	for _, ix := range is {
+		row = append(row, string(s[ix[0]:ix[1]]))
 	}
+	return row
+}
 
-	lp, err := cmpl(LinePattern)
-	if err != nil {
-		return
+func submatches(lp *regexp.Regexp, line []byte) []string {
+	//index 0 is the complete match, as with FindSubmatch
+	sms := lp.FindSubmatch(line)
+	if sms == nil {
+		return nil
 	}
+	row := make([]string, 0, len(sms))
+	for _, sm := range sms {
+		row = append(row, string(sm))
+	}
+	return row
+}
 
-	//code loosely based on but entirely inspired by rsc's reply to
-	//https://groups.google.com/forum/#!topic/golang-nuts/4LpRZDfNXIc
+func nameRow(names map[string]int, row []string) map[string]string {
+	out := make(map[string]string, len(names))
+	for name, i := range names {
+		if i < len(row) {
+			out[name] = row[i]
+		} else {
+			out[name] = ""
+		}
+	}
+	return out
+}
+
+func submatchNames(header []string, lp *regexp.Regexp) (map[string]int, error) {
 	if lp.NumSubexp() < 1 {
 		return nil, errors.New("submatch splitting requires a regexp with submatches")
 	}
@@ -55,6 +93,58 @@ func SubmatchSplit(header []string, RS, LinePattern string, Stdin io.Reader) (re
 			}
 		}
 	}
+	return names, nil
+}
+
+func headerSubmatches(lp *regexp.Regexp, line []byte) (map[string]int, error) {
+	sms := submatches(lp, line)
+	if sms == nil {
+		return nil, fmt.Errorf("header line %q does not match line pattern", line)
+	}
+	return hdr2map(sms[1:], true), nil
+}
+
+func recordNumbers(seps, records [][]int) []int {
+	//the NR of each record, counting the empty records between separators
+	//so that with -R \n it is the line number
+	nrs := make([]int, len(records))
+	n, j := 0, 0
+	for i, r := range records {
+		for ; j < len(seps) && seps[j][1] <= r[0]; j++ {
+			if seps[j][1] > seps[j][0] {
+				n++
+			}
+		}
+		nrs[i] = n + 1
+	}
+	return nrs
+}
+
+func SubmatchSplit(header []string, RS, LinePattern string, Stdin io.Reader) (ret interface{}, err error) {
+	rs, err := cmpl(RS) //might as well add these to the cache
+	if err != nil {
+		return
+	}
+
+	lp, err := cmpl(LinePattern)
+	if err != nil {
+		return
+	}
+
+	//code loosely based on but entirely inspired by rsc's reply to
+	//https://groups.google.com/forum/#!topic/golang-nuts/4LpRZDfNXIc
+	header, types, err := headerTypes(header)
+	if err != nil {
+		return
+	}
+	first := isFirstLine(header)
+	if first {
+		header = nil
+	}
+	names, err := submatchNames(header, lp)
+	if err != nil {
+		return
+	}
 
 	stdin, err := ioutil.ReadAll(Stdin)
 	if err != nil {
@@ -63,37 +153,41 @@ func SubmatchSplit(header []string, RS, LinePattern string, Stdin io.Reader) (re
 
 	pairs := rs.FindAllIndex(stdin, -1)
 	records := invert.Indicies(pairs, len(stdin))
-	if len(names) > 0 {
+	if first && len(records) > 0 {
+		p := records[0]
+		if names, err = headerSubmatches(lp, stdin[p[0]:p[1]]); err != nil {
+			return
+		}
+		records = records[1:]
+	}
+	if len(names) > 0 || first {
 		//if there are named submatches build the row as a map with just the named entries.
 		//multiple names are by construction the value of the last name.
-		out := make([]map[string]string, 0, len(records))
-		for _, p := range records {
-			if sms := lp.FindSubmatch(stdin[p[0]:p[1]]); sms != nil {
-				row := make(map[string]string, len(names))
-				for name, i := range names {
-					if i < len(sms) {
-						row[name] = string(sms[i])
-					} else {
-						row[name] = ""
-					}
+		out := newNamer(names, types)
+		nrs := recordNumbers(pairs, records)
+		for i, p := range records {
+			if sms := submatches(lp, stdin[p[0]:p[1]]); sms != nil {
+				if err = out.add(nrs[i]-1, sms); err != nil {
+					return nil, err
 				}
-				out = append(out, row)
 			}
 
 		}
-		ret = out
+		ret = out.rows()
 	} else {
 		out := make([]*record, 0, len(records))
-		for _, p := range records {
+		name := filename(Stdin)
+		nrs := recordNumbers(pairs, records)
+		for i, p := range records {
 			line := stdin[p[0]:p[1]]
-			if sms := lp.FindSubmatch(line); sms != nil {
-				row := make([]string, 0, len(sms)-1)
-				for _, sm := range sms[1:] {
-					row = append(row, string(sm))
-				}
+			if sms := submatches(lp, line); sms != nil {
 				out = append(out, &record{
-					Fields: row,
-					Line:   string(line),
+					Fields:   sms[1:],
+					Line:     string(line),
+					NR:       nrs[i],
+					FILENAME: name,
+					Start:    p[0],
+					End:      p[1],
 				})
 			}
 		}
@@ -114,51 +208,51 @@ func Split(header []string, RS, FS string, Stdin io.Reader) (ret interface{}, er
 		return
 	}
 
-	stdin, err := ioutil.ReadAll(Stdin)
+	header, types, err := headerTypes(header)
 	if err != nil {
 		return
 	}
 
-	names := hdr2map(header, false)
+	stdin, err := ioutil.ReadAll(Stdin)
+	if err != nil {
+		return
+	}
 
 	pairs := rs.FindAllIndex(stdin, -1)
 	records := invert.Indicies(pairs, len(stdin))
-	if len(names) > 0 {
-		out := make([]map[string]string, 0, len(records))
-		for _, p := range records {
-			s := stdin[p[0]:p[1]]
 
-			is := fs.FindAllIndex(s, -1)
-			is = invert.Indicies(is, len(s))
+	first := isFirstLine(header)
+	if first {
+		header = nil
+		if len(records) > 0 {
+			p := records[0]
+			header, records = splitFields(fs, stdin[p[0]:p[1]]), records[1:]
+		}
+	}
+	names := hdr2map(header, false)
 
-			row := make(map[string]string, len(names))
-			for name, i := range names {
-				if i < len(is) {
-					ix := is[i]
-					row[name] = string(s[ix[0]:ix[1]])
-				} else {
-					row[name] = ""
-				}
+	if len(names) > 0 || first {
+		out := newNamer(names, types)
+		nrs := recordNumbers(pairs, records)
+		for i, p := range records {
+			if err = out.add(nrs[i]-1, splitFields(fs, stdin[p[0]:p[1]])); err != nil {
+				return nil, err
 			}
-			out = append(out, row)
 		}
-		ret = out
+		ret = out.rows()
 	} else {
 		out := make([]*record, 0, len(records))
-		for _, p := range records {
+		name := filename(Stdin)
+		nrs := recordNumbers(pairs, records)
+		for i, p := range records {
 			s := stdin[p[0]:p[1]]
-
-			is := fs.FindAllIndex(s, -1)
-			is = invert.Indicies(is, len(s))
-
-			row := make([]string, 0, len(is))
-			for _, ix := range is {
-				row = append(row, string(s[ix[0]:ix[1]]))
-			}
-
 			out = append(out, &record{
-				Fields: row,
-				Line:   string(s),
+				Fields:   splitFields(fs, s),
+				Line:     string(s),
+				NR:       nrs[i],
+				FILENAME: name,
+				Start:    p[0],
+				End:      p[1],
 			})
 		}
 		ret = out
@@ -168,6 +262,13 @@ func Split(header []string, RS, FS string, Stdin io.Reader) (ret interface{}, er
 }
 
 func CSV(header []string, Stdin io.Reader) (interface{}, error) {
+	header, types, err := headerTypes(header)
+	if err != nil {
+		return nil, err
+	}
+	if isFirstLine(header) {
+		header = nil
+	}
 	r := csv.NewReader(Stdin)
 	r.LazyQuotes = true
 	r.TrimLeadingSpace = true
@@ -186,19 +287,21 @@ func CSV(header []string, Stdin io.Reader) (interface{}, error) {
 		header, recs = recs[0], recs[1:]
 	}
 
-	rows := []map[string]string{}
+	names := make(map[string]int, len(header))
+	for i, h := range header {
+		names[h] = i
+	}
+	rows := newNamer(names, types)
 	for rn, rec := range recs {
 		if h, r := len(header), len(rec); h != r {
 			return nil, fmt.Errorf("%d: row len %d ≠ header len %d", rn, r, h)
 		}
-		row := map[string]string{}
-		for i, h := range header {
-			row[h] = rec[i]
+		if err := rows.add(rn, rec); err != nil {
+			return nil, err
 		}
-		rows = append(rows, row)
 	}
 
-	return rows, nil
+	return rows.rows(), nil
 }
 
 func JSON(Stdin io.Reader) (out interface{}, err error) {
@@ -209,3 +312,133 @@ func JSON(Stdin io.Reader) (out interface{}, err error) {
 	err = json.Unmarshal(stdin, &out)
 	return
 }
+
+func JSONL(Stdin io.Reader) (out []interface{}, err error) {
+	stdin, err := ioutil.ReadAll(Stdin)
+	if err != nil {
+		return
+	}
+	d := json.NewDecoder(bytes.NewReader(stdin))
+	for {
+		var v interface{}
+		if err = d.Decode(&v); err == io.EOF {
+			return out, nil
+		} else if err != nil {
+			//report the line of the error, not the byte offset
+			at := d.InputOffset()
+			switch e := err.(type) {
+			case *json.SyntaxError:
+				at = e.Offset
+			case *json.UnmarshalTypeError:
+				at = e.Offset
+			}
+			if at > int64(len(stdin)) {
+				at = int64(len(stdin))
+			}
+			line := bytes.Count(stdin[:at], []byte("\n")) + 1
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		out = append(out, v)
+	}
+}
+
+func TOML(Stdin io.Reader) (out interface{}, err error) {
+	var m map[string]interface{}
+	if _, err = toml.NewDecoder(Stdin).Decode(&m); err != nil {
+		return
+	}
+	return m, nil
+}
+
+func YAML(Stdin io.Reader) (out interface{}, err error) {
+	var docs []interface{}
+	d := yaml.NewDecoder(Stdin)
+	for {
+		var doc interface{}
+		if err = d.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		docs = append(docs, stringKeys(doc))
+	}
+	//a single document is the common case, so only use a list for streams
+	switch len(docs) {
+	case 0:
+		return nil, nil
+	case 1:
+		return docs[0], nil
+	}
+	return docs, nil
+}
+
+func stringKeys(v interface{}) interface{} {
+	//YAML allows keys of any type but templates can only index maps with string keys
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = stringKeys(e)
+		}
+		return m
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = stringKeys(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = stringKeys(e)
+		}
+	}
+	return v
+}
+
+func XML(Stdin io.Reader) (interface{}, error) {
+	d := xml.NewDecoder(Stdin)
+	var (
+		root  map[string]interface{}
+		stack []map[string]interface{}
+		text  []*strings.Builder
+	)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			//# and @ cannot begin an XML name so these never collide with children
+			el := map[string]interface{}{
+				"#name":     t.Name.Local,
+				"#children": []interface{}{},
+			}
+			for _, a := range t.Attr {
+				el["@"+a.Name.Local] = a.Value
+			}
+			if n := len(stack); n > 0 {
+				parent := stack[n-1]
+				parent["#children"] = append(parent["#children"].([]interface{}), el)
+				siblings, _ := parent[t.Name.Local].([]interface{})
+				parent[t.Name.Local] = append(siblings, el)
+			} else if root == nil {
+				root = el
+			}
+			stack = append(stack, el)
+			text = append(text, &strings.Builder{})
+		case xml.EndElement:
+			n := len(stack) - 1
+			stack[n]["#text"] = strings.TrimSpace(text[n].String())
+			stack, text = stack[:n], text[:n]
+		case xml.CharData:
+			if n := len(text); n > 0 {
+				text[n-1].Write(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, nil
+	}
+	return root, nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"sort"
+	"strings"
 	"testing"
 )
 
@@ -243,3 +244,137 @@ func TestSubmatchSplitHeaderNames(t *testing.T) {
 		failIf(t, i, listMapEquals(v.out, ret.([]map[string]string)))
 	}
 }
+
+//TEST YAML
+
+func TestYAML(t *testing.T) {
+	ret, err := YAML(rdr("a: 1\nb:\n  1: x\n"))
+	failIf(t, 0, err)
+	m, ok := ret.(map[string]interface{})
+	if !ok {
+		t.Fatalf("wrong return type, expected map[string]interface{}, got %T", ret)
+	}
+	if _, ok := m["b"].(map[string]interface{}); !ok {
+		t.Errorf("nested map has wrong type %T", m["b"])
+	}
+
+	ret, err = YAML(rdr("a: 1\n---\na: 2\n"))
+	failIf(t, 1, err)
+	if docs, ok := ret.([]interface{}); !ok || len(docs) != 2 {
+		t.Errorf("expected list of 2 documents, got %#v", ret)
+	}
+}
+
+//TEST TOML
+
+func TestTOML(t *testing.T) {
+	ret, err := TOML(rdr("name = \"txt\"\n[dep]\nversion = 2\n"))
+	failIf(t, 0, err)
+	m, ok := ret.(map[string]interface{})
+	if !ok {
+		t.Fatalf("wrong return type, expected map[string]interface{}, got %T", ret)
+	}
+	if m["name"] != "txt" {
+		t.Errorf("name: %#v ≠ %#v", m["name"], "txt")
+	}
+	if _, ok := m["dep"].(map[string]interface{}); !ok {
+		t.Errorf("table has wrong type %T", m["dep"])
+	}
+}
+
+//TEST JSONL
+
+func TestJSONL(t *testing.T) {
+	ret, err := JSONL(rdr("{\"a\": 1}\n\n{\"a\": 2}\n[3]\n"))
+	failIf(t, 0, err)
+	if len(ret) != 3 {
+		t.Errorf("wrong number of values: %d ≠ 3", len(ret))
+	}
+
+	_, err = JSONL(rdr("{\"a\": 1}\n{\"a\": 2}\n{\"a\" 3}\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3: ") {
+		t.Errorf("wrong error returned: %v", err)
+	}
+}
+
+//TEST XML
+
+func TestXML(t *testing.T) {
+	ret, err := XML(rdr(`<?xml version="1.0"?><a x="1"> hi <b>one</b><c/><b>two</b></a>`))
+	failIf(t, 0, err)
+	a, ok := ret.(map[string]interface{})
+	if !ok {
+		t.Fatalf("wrong return type, expected map[string]interface{}, got %T", ret)
+	}
+	if a["#name"] != "a" || a["@x"] != "1" || a["#text"] != "hi" {
+		t.Errorf("wrong root element: %#v", a)
+	}
+	if n := len(a["#children"].([]interface{})); n != 3 {
+		t.Errorf("wrong number of children: %d ≠ 3", n)
+	}
+	bs := a["b"].([]interface{})
+	var got []string
+	for _, b := range bs {
+		got = append(got, b.(map[string]interface{})["#text"].(string))
+	}
+	failIf(t, 0, listEquals(0, []string{"one", "two"}, got))
+}
+
+//TEST HEADER LINE
+
+func TestSplitFirstLine(t *testing.T) {
+	ret, err := Split([]string{FirstLine}, RS, FS, rdr("a b c\n1 2 3\n4 5\n"))
+	failIf(t, 0, err)
+	failIf(t, 0, listMapEquals([]map[string]string{
+		{"a": "1", "b": "2", "c": "3"},
+		{"a": "4", "b": "5", "c": ""},
+	}, ret.([]map[string]string)))
+}
+
+func TestSubmatchSplitFirstLine(t *testing.T) {
+	LP := `(\S+)\s*=\s*(\S+)`
+	ret, err := SubmatchSplit([]string{FirstLine}, RS, LP, rdr("k = v\na = 1\nskip\nb = 2\n"))
+	failIf(t, 0, err)
+	failIf(t, 0, listMapEquals([]map[string]string{
+		{"k": "a", "v": "1"},
+		{"k": "b", "v": "2"},
+	}, ret.([]map[string]string)))
+
+	_, err = SubmatchSplit([]string{FirstLine}, RS, LP, rdr("skip\na = 1\n"))
+	if err == nil {
+		t.Error("expected error for header line not matching line pattern")
+	}
+}
+
+//TEST RECORD METADATA
+
+func TestRecordMetadata(t *testing.T) {
+	corpus := "a b\n\nc d e\nf"
+	ret, err := Split(nil, RS, FS, rdr(corpus))
+	failIf(t, 0, err)
+	recs := ret.([]*record)
+	want := []struct{ NR, NF, Start, End int }{
+		{1, 2, 0, 3},
+		{2, 3, 5, 10},
+		{3, 1, 11, 12},
+	}
+	if len(recs) != len(want) {
+		t.Fatalf("wrong number of records: %d ≠ %d", len(recs), len(want))
+	}
+	for i, w := range want {
+		r := recs[i]
+		if r.NR != w.NR || r.NF() != w.NF || r.Start != w.Start || r.End != w.End {
+			t.Errorf("record %d: got %d %d %d %d, expected %v", i, r.NR, r.NF(), r.Start, r.End, w)
+		}
+		if corpus[r.Start:r.End] != r.Line {
+			t.Errorf("record %d: offsets do not match line %q", i, r.Line)
+		}
+	}
+
+	ret, err = SubmatchSplit(nil, "\n", `(\d+)`, rdr("x\n1\ny\n2"))
+	failIf(t, 1, err)
+	recs = ret.([]*record)
+	if len(recs) != 2 || recs[0].NR != 2 || recs[1].NR != 4 {
+		t.Errorf("NR should count unmatched records: %v", recs)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+type sortKey struct {
+	field   interface{} //nil for the item itself
+	numeric bool
+	natural bool
+	reverse bool
+}
+
+func parseSortKey(k interface{}) (key sortKey, err error) {
+	//a string key is name:options, where options are like those of sort(1)
+	s, ok := k.(string)
+	if !ok {
+		return sortKey{field: k}, nil
+	}
+	name, opts := s, ""
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		name, opts = s[:i], s[i+1:]
+	}
+	if name != "" {
+		key.field = name
+	}
+	for _, o := range opts {
+		switch o {
+		case 'n':
+			key.numeric = true
+		case 'V':
+			key.natural = true
+		case 'r':
+			key.reverse = true
+		default:
+			return key, fmt.Errorf("unknown sort option %q in %q", o, s)
+		}
+	}
+	if key.numeric && key.natural {
+		return key, fmt.Errorf("sort key %q cannot be both numeric and natural", s)
+	}
+	return key, nil
+}
+
+const (
+	//the kinds of sort values, in the order they sort
+	sortEmpty = iota
+	sortNumber
+	sortTime
+	sortString
+)
+
+type sortValue struct {
+	kind int
+	n    float64
+	t    time.Time
+	s    string
+}
+
+func (k sortKey) value(item interface{}) (sv sortValue, err error) {
+	v := item
+	if k.field != nil {
+		if v, err = field(item, k.field); err != nil {
+			return
+		}
+	}
+	if v == nil || v == "" {
+		return sortValue{kind: sortEmpty}, nil
+	}
+	if k.numeric {
+		n, err := toNumber(v)
+		if err != nil {
+			return sv, err
+		}
+		return sortValue{kind: sortNumber, n: n.float()}, nil
+	}
+	if !k.natural {
+		if t, ok := v.(time.Time); ok {
+			return sortValue{kind: sortTime, t: t}, nil
+		}
+		switch reflect.ValueOf(v).Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			n, err := toNumber(v)
+			if err != nil {
+				return sv, err
+			}
+			return sortValue{kind: sortNumber, n: n.float()}, nil
+		}
+	}
+	return sortValue{kind: sortString, s: fmt.Sprint(v)}, nil
+}
+
+func (k sortKey) compare(a, b sortValue) (c int) {
+	switch {
+	case a.kind != b.kind:
+		c = a.kind - b.kind
+	case a.kind == sortNumber:
+		c = compareFloats(a.n, b.n)
+	case a.kind == sortTime:
+		c = a.t.Compare(b.t)
+	case a.kind == sortString && k.natural:
+		c = compareNatural(a.s, b.s)
+	case a.kind == sortString:
+		c = strings.Compare(a.s, b.s)
+	}
+	if k.reverse {
+		c = -c
+	}
+	return c
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func isDigit(b byte) bool {
+	return '0' <= b && b <= '9'
+}
+
+func compareNatural(a, b string) int {
+	//runs of digits compare numerically, everything else compares bytewise,
+	//so that file2 sorts before file10
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			i, j := 0, 0
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+			x, y := strings.TrimLeft(a[:i], "0"), strings.TrimLeft(b[:j], "0")
+			if len(x) != len(y) {
+				return len(x) - len(y)
+			}
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+			a, b = a[i:], b[j:]
+			continue
+		}
+		if a[0] != b[0] {
+			return int(a[0]) - int(b[0])
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) - len(b)
+}
+
+func sortBy(list interface{}, keys ...interface{}) (interface{}, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("sortBy requires at least one key")
+	}
+	is, err := items(list)
+	if err != nil || is == nil {
+		return list, err
+	}
+
+	ks := make([]sortKey, len(keys))
+	for i, k := range keys {
+		if ks[i], err = parseSortKey(k); err != nil {
+			return nil, err
+		}
+	}
+
+	//compute every value first so errors halt execution before sorting
+	vals := make([][]sortValue, len(is))
+	for i, it := range is {
+		vals[i] = make([]sortValue, len(ks))
+		for j, k := range ks {
+			if vals[i][j], err = k.value(it); err != nil {
+				return nil, fmt.Errorf("item %d: %s", i, err)
+			}
+		}
+	}
+
+	idx := make([]int, len(is))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(x, y int) bool {
+		a, b := vals[idx[x]], vals[idx[y]]
+		for j, k := range ks {
+			if c := k.compare(a[j], b[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return subset(list, idx), nil
+}
+
+func sortList(list interface{}, opts ...string) (interface{}, error) {
+	switch len(opts) {
+	case 0:
+		return sortBy(list, "")
+	case 1:
+		return sortBy(list, ":"+opts[0])
+	}
+	return nil, fmt.Errorf("sort takes 1 or 2 arguments, given %d", len(opts)+1)
+}
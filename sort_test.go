@@ -0,0 +1,56 @@
+package main
+
+import "testing"
+
+func TestSortBy(t *testing.T) {
+	ret, err := Split(splitHeader("host,bytes"), RS, FS, rdr("b 10\na 9\nb 100\na 20\n"))
+	failIf(t, 0, err)
+
+	sorted, err := sortBy(ret, "host", "bytes:nr")
+	failIf(t, 0, err)
+	failIf(t, 0, listMapEquals([]map[string]string{
+		{"host": "a", "bytes": "20"},
+		{"host": "a", "bytes": "9"},
+		{"host": "b", "bytes": "100"},
+		{"host": "b", "bytes": "10"},
+	}, sorted.([]map[string]string)))
+
+	sorted, err = sortBy(ret, "bytes")
+	failIf(t, 1, err)
+	if b := sorted.([]map[string]string)[0]["bytes"]; b != "10" {
+		t.Errorf("lexical sort: first is %s, expected 10", b)
+	}
+
+	if _, err := sortBy(ret, "host:n"); err == nil {
+		t.Error("expected error sorting non-numbers numerically")
+	}
+	if _, err := sortBy(ret, "host:x"); err == nil {
+		t.Error("expected error for unknown option")
+	}
+}
+
+func TestSortByRecords(t *testing.T) {
+	ret, err := Split(nil, RS, FS, rdr("x file10\ny file2\nz file1\n"))
+	failIf(t, 0, err)
+	sorted, err := sortBy(ret, "1:V")
+	failIf(t, 0, err)
+	recs, err := fields(sorted)
+	failIf(t, 0, err)
+	failIf(t, 0, listListEquals([][]string{
+		{"z", "file1"},
+		{"y", "file2"},
+		{"x", "file10"},
+	}, recs))
+}
+
+func TestSortList(t *testing.T) {
+	sorted, err := sortList([]string{"10", "9", "100"}, "n")
+	failIf(t, 0, err)
+	failIf(t, 0, listEquals(0, []string{"9", "10", "100"}, sorted.([]string)))
+
+	sorted, err = sortList([]interface{}{3.5, 1.0, 2.0})
+	failIf(t, 1, err)
+	if s := sorted.([]interface{}); s[0] != 1.0 || s[2] != 3.5 {
+		t.Errorf("wrong order: %v", s)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+func isList(v interface{}) bool {
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Slice, reflect.Array:
+		return true
+	}
+	return false
+}
+
+func numbersOf(list interface{}, key []interface{}) ([]number, error) {
+	vs, err := fieldOf(list, key)
+	if err != nil {
+		return nil, err
+	}
+	return toNumbers(vs)
+}
+
+func floatsOf(list interface{}, key []interface{}) ([]float64, error) {
+	ns, err := numbersOf(list, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ns) == 0 {
+		return nil, errors.New("empty list")
+	}
+	fs := make([]float64, len(ns))
+	for i, n := range ns {
+		fs[i] = n.float()
+	}
+	return fs, nil
+}
+
+func sum(list interface{}, key ...interface{}) (interface{}, error) {
+	vs, err := fieldOf(list, key)
+	if err != nil {
+		return nil, err
+	}
+	return add(append([]interface{}{0}, vs...)...)
+}
+
+func mean(list interface{}, key ...interface{}) (interface{}, error) {
+	fs, err := floatsOf(list, key)
+	if err != nil {
+		return nil, err
+	}
+	t := 0.0
+	for _, f := range fs {
+		t += f
+	}
+	return t / float64(len(fs)), nil
+}
+
+func percentileOf(fs []float64, p float64) (float64, error) {
+	if p < 0 || p > 100 {
+		return 0, fmt.Errorf("percentile %g is not between 0 and 100", p)
+	}
+	sort.Float64s(fs)
+	//linear interpolation between the closest ranks
+	r := p / 100 * float64(len(fs)-1)
+	lo := int(math.Floor(r))
+	hi := int(math.Ceil(r))
+	return fs[lo] + (fs[hi]-fs[lo])*(r-float64(lo)), nil
+}
+
+func median(list interface{}, key ...interface{}) (interface{}, error) {
+	fs, err := floatsOf(list, key)
+	if err != nil {
+		return nil, err
+	}
+	return percentileOf(fs, 50)
+}
+
+func percentile(list, p interface{}, key ...interface{}) (interface{}, error) {
+	n, err := toNumber(p)
+	if err != nil {
+		return nil, err
+	}
+	fs, err := floatsOf(list, key)
+	if err != nil {
+		return nil, err
+	}
+	return percentileOf(fs, n.float())
+}
+
+func stddev(list interface{}, key ...interface{}) (interface{}, error) {
+	fs, err := floatsOf(list, key)
+	if err != nil {
+		return nil, err
+	}
+	m := 0.0
+	for _, f := range fs {
+		m += f
+	}
+	m /= float64(len(fs))
+	v := 0.0
+	for _, f := range fs {
+		v += (f - m) * (f - m)
+	}
+	return math.Sqrt(v / float64(len(fs))), nil
+}
+
+func count(list interface{}, key ...interface{}) (int, error) {
+	vs, err := fieldOf(list, key)
+	if err != nil {
+		return 0, err
+	}
+	if len(key) == 0 {
+		return len(vs), nil
+	}
+	n := 0
+	for _, v := range vs {
+		if v != nil && v != "" {
+			n++
+		}
+	}
+	return n, nil
+}
+
+//min and max of a list or of numbers
+
+func extremum(name string, f func(...interface{}) (interface{}, error), vs []interface{}) (interface{}, error) {
+	if len(vs) == 0 || !isList(vs[0]) {
+		return f(vs...)
+	}
+	ns, err := fieldOf(vs[0], vs[1:])
+	if err != nil {
+		return nil, err
+	}
+	if len(ns) == 0 {
+		return nil, fmt.Errorf("%s of empty list", name)
+	}
+	return f(ns...)
+}
+
+func minOf(vs ...interface{}) (interface{}, error) {
+	return extremum("min", minimum, vs)
+}
+
+func maxOf(vs ...interface{}) (interface{}, error) {
+	return extremum("max", maximum, vs)
+}
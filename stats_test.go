@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	ret, err := Split(splitHeader("host,bytes"), RS, FS, rdr("a 10\nb 20\na 30\nc 40\n"))
+	failIf(t, 0, err)
+
+	check := func(name string, out interface{}, err error, want interface{}) {
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+		} else if out != want {
+			t.Errorf("%s: %#v ≠ %#v", name, out, want)
+		}
+	}
+	out, err := sum(ret, "bytes")
+	check("sum", out, err, 100)
+	out, err = mean(ret, "bytes")
+	check("mean", out, err, 25.0)
+	out, err = median(ret, "bytes")
+	check("median", out, err, 25.0)
+	out, err = percentile(ret, 100, "bytes")
+	check("percentile", out, err, 40.0)
+	out, err = minOf(ret, "bytes")
+	check("min", out, err, 10)
+	out, err = maxOf(ret, "bytes")
+	check("max", out, err, 40)
+	n, err := count(ret)
+	check("count", n, err, 4)
+	out, err = minOf(3, 2)
+	check("min numbers", out, err, 2)
+
+	out, err = stddev([]int{2, 4, 4, 4, 5, 5, 7, 9})
+	check("stddev", out, err, 2.0)
+
+	recs, err := Split(nil, RS, FS, rdr("x 1.5\ny 2.5\n"))
+	failIf(t, 1, err)
+	out, err = sum(recs, 1)
+	check("sum records", out, err, 4.0)
+
+	if _, err := mean([]string{}); err == nil {
+		t.Error("expected error for mean of empty list")
+	}
+	if _, err := percentile([]int{1}, 101); err == nil {
+		t.Error("expected error for percentile out of range")
+	}
+	if f, _ := percentile([]int{1, 2, 3, 4}, 25); math.Abs(f.(float64)-1.75) > 1e-9 {
+		t.Errorf("percentile: %v ≠ 1.75", f)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import "fmt"
+
+type store struct {
+	//the variables shared by every template of an execution
+	vars map[string]interface{}
+}
+
+func newStore() *store {
+	return &store{vars: map[string]interface{}{}}
+}
+
+func (s *store) funcs() map[string]interface{} {
+	return map[string]interface{}{
+		"set":  s.set,
+		"get":  s.get,
+		"incr": s.incr,
+		"push": s.push,
+	}
+}
+
+func (s *store) set(args ...interface{}) (string, error) {
+	//set name value, or set map key value as set on maps
+	switch len(args) {
+	case 2:
+		name, ok := args[0].(string)
+		if !ok {
+			return "", fmt.Errorf("variable name %v is not a string", args[0])
+		}
+		s.vars[name] = args[1]
+		return "", nil
+	case 3:
+		return setKey(args[0], args[1], args[2])
+	}
+	return "", fmt.Errorf("set takes 2 or 3 arguments, given %d", len(args))
+}
+
+func (s *store) get(name string) interface{} {
+	return s.vars[name]
+}
+
+func (s *store) incr(name string, by ...interface{}) (string, error) {
+	if len(by) > 1 {
+		return "", fmt.Errorf("incr takes 1 or 2 arguments, given %d", len(by)+1)
+	}
+	n := interface{}(0)
+	if v, ok := s.vars[name]; ok {
+		n = v
+	}
+	d := interface{}(1)
+	if len(by) == 1 {
+		d = by[0]
+	}
+	v, err := add(n, d)
+	if err != nil {
+		return "", fmt.Errorf("incr %s: %s", name, err)
+	}
+	s.vars[name] = v
+	return "", nil
+}
+
+func (s *store) push(name string, vs ...interface{}) (string, error) {
+	l, err := appendList(s.vars[name], vs...)
+	if err != nil {
+		return "", fmt.Errorf("push %s: %s", name, err)
+	}
+	s.vars[name] = l
+	return "", nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	const src = `{{define "total"}}{{get "n"}} {{get "bytes"}} {{range get "seen"}}{{.}}{{end}}{{end}}` +
+		`{{range .}}{{incr "n"}}{{incr "bytes" .}}{{push "seen" .}}{{end}}` +
+		`{{$m := dict}}{{set $m "k" 1}}{{set "m" $m}}{{template "total"}} {{(get "m").k}}`
+	tmpl, err := Parse(false, src, "{{", "}}", funcs)
+	failIf(t, 0, err)
+	var b bytes.Buffer
+	failIf(t, 0, tmpl.ExecuteTemplate(&b, "", []string{"1", "2", "3"}))
+	if out := b.String(); out != "3 6 123 1" {
+		t.Errorf("expected %q, got %q", "3 6 123 1", out)
+	}
+
+	//each Parse has its own store
+	tmpl, err = Parse(false, `{{get "n"}}`, "{{", "}}", funcs)
+	failIf(t, 0, err)
+	b.Reset()
+	failIf(t, 0, tmpl.ExecuteTemplate(&b, "", nil))
+	if out := b.String(); out != "<no value>" {
+		t.Errorf("expected empty store, got %q", out)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import "io"
+
+const chunk = 4096
+
+func Stream(RS string, Stdin io.Reader, each func(line []byte, nr, start int) error) error {
+	//each is called on every nonempty record as soon as its separator is read,
+	//so only the current record and any unread input is ever held in memory.
+	//nr counts the empty records as well, as with recordNumbers.
+	rs, err := cmpl(RS)
+	if err != nil {
+		return err
+	}
+
+	var buf []byte
+	off := 0 //of buf in Stdin
+	seps := 0
+	eof := false
+	for {
+		//a match that would consume nothing cannot make progress,
+		//so keep reading until there is more to split or no more to read.
+		if loc := rs.FindIndex(buf); loc != nil && loc[1] > 0 {
+			if loc[1] < len(buf) || eof {
+				if loc[0] > 0 {
+					if err := each(buf[:loc[0]], seps+1, off); err != nil {
+						return err
+					}
+				}
+				seps++
+				buf = buf[loc[1]:]
+				off += loc[1]
+				continue
+			}
+			//the separator may continue past what has been read,
+			//so emit the record but match the separator again after reading.
+			if loc[0] > 0 {
+				if err := each(buf[:loc[0]], seps+1, off); err != nil {
+					return err
+				}
+				buf = buf[loc[0]:]
+				off += loc[0]
+			}
+		} else if eof {
+			if len(buf) > 0 {
+				return each(buf, seps+1, off)
+			}
+			return nil
+		}
+
+		if len(buf) == cap(buf) {
+			nb := make([]byte, len(buf), 2*cap(buf)+chunk)
+			copy(nb, buf)
+			buf = nb
+		}
+		n, err := Stdin.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err == io.EOF {
+			eof = true
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func StreamSplit(header []string, RS, FS string, Stdin io.Reader, each func(interface{}) error) error {
+	fs, err := cmpl(FS)
+	if err != nil {
+		return err
+	}
+
+	header, types, err := headerTypes(header)
+	if err != nil {
+		return err
+	}
+	first := isFirstLine(header)
+	var rows *namer
+	if names := hdr2map(header, false); len(names) > 0 && !first {
+		rows = newNamer(names, types)
+	}
+
+	name := filename(Stdin)
+	return Stream(RS, Stdin, func(s []byte, nr, start int) error {
+		if first && rows == nil {
+			rows = newNamer(hdr2map(splitFields(fs, s), false), nil)
+			return nil
+		}
+		if rows != nil {
+			r, err := rows.row(nr-1, splitFields(fs, s))
+			if err != nil {
+				return err
+			}
+			return each(r)
+		}
+		return each(&record{
+			Fields:   splitFields(fs, s),
+			Line:     string(s),
+			NR:       nr,
+			FILENAME: name,
+			Start:    start,
+			End:      start + len(s),
+		})
+	})
+}
+
+func StreamSubmatchSplit(header []string, RS, LinePattern string, Stdin io.Reader, each func(interface{}) error) error {
+	lp, err := cmpl(LinePattern)
+	if err != nil {
+		return err
+	}
+
+	header, types, err := headerTypes(header)
+	if err != nil {
+		return err
+	}
+	first := isFirstLine(header)
+	if first {
+		header = nil
+	}
+	names, err := submatchNames(header, lp)
+	if err != nil {
+		return err
+	}
+	var rows *namer
+	if len(names) > 0 && !first {
+		rows = newNamer(names, types)
+	}
+
+	name := filename(Stdin)
+	return Stream(RS, Stdin, func(line []byte, nr, start int) error {
+		if first && rows == nil {
+			names, err := headerSubmatches(lp, line)
+			if err != nil {
+				return err
+			}
+			rows = newNamer(names, nil)
+			return nil
+		}
+		sms := submatches(lp, line)
+		if sms == nil {
+			return nil
+		}
+		if rows != nil {
+			r, err := rows.row(nr-1, sms)
+			if err != nil {
+				return err
+			}
+			return each(r)
+		}
+		return each(&record{
+			Fields:   sms[1:],
+			Line:     string(line),
+			NR:       nr,
+			FILENAME: name,
+			Start:    start,
+			End:      start + len(line),
+		})
+	})
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"testing"
+	"testing/iotest"
+)
+
+func TestStreamSplitNoHeader(t *testing.T) {
+	for i, v := range splitNoHeaderTests {
+		var recs [][]string
+		err := StreamSplit(nil, RS, FS, iotest.OneByteReader(rdr(v.corpus)), func(rec interface{}) error {
+			recs = append(recs, rec.(*record).Fields)
+			return nil
+		})
+		failIf(t, i, err)
+		failIf(t, i, listListEquals(v.out, recs))
+	}
+}
+
+func TestStreamSplitHeader(t *testing.T) {
+	for i, v := range splitHeaderTests {
+		var rows []map[string]string
+		err := StreamSplit(v.header, RS, FS, rdr(v.corpus), func(rec interface{}) error {
+			rows = append(rows, rec.(map[string]string))
+			return nil
+		})
+		failIf(t, i, err)
+		failIf(t, i, listMapEquals(v.out, rows))
+	}
+}
+
+func TestStreamSubmatchSplitNames(t *testing.T) {
+	for i, v := range submatchNoHeaderNamesTests {
+		var rows []map[string]string
+		err := StreamSubmatchSplit(v.header, RS, v.LP, iotest.OneByteReader(rdr(v.corpus)), func(rec interface{}) error {
+			rows = append(rows, rec.(map[string]string))
+			return nil
+		})
+		failIf(t, i, err)
+		failIf(t, i, listMapEquals(v.out, rows))
+	}
+}
+
+func TestStreamSplitFirstLine(t *testing.T) {
+	var rows []map[string]string
+	err := StreamSplit([]string{FirstLine}, RS, FS, rdr("a b\n1 2\n3 4\n"), func(rec interface{}) error {
+		rows = append(rows, rec.(map[string]string))
+		return nil
+	})
+	failIf(t, 0, err)
+	failIf(t, 0, listMapEquals([]map[string]string{
+		{"a": "1", "b": "2"},
+		{"a": "3", "b": "4"},
+	}, rows))
+}
+
+func TestStreamRecordMetadata(t *testing.T) {
+	corpus := "a b\n\nc d e\nf"
+	var recs []*record
+	err := StreamSplit(nil, RS, FS, iotest.OneByteReader(rdr(corpus)), func(rec interface{}) error {
+		recs = append(recs, rec.(*record))
+		return nil
+	})
+	failIf(t, 0, err)
+	for i, r := range recs {
+		if r.NR != i+1 || corpus[r.Start:r.End] != r.Line {
+			t.Errorf("record %d: wrong metadata NR=%d Start=%d End=%d", i, r.NR, r.Start, r.End)
+		}
+	}
+}
+
+func TestStreamSplitSeparatorAcrossReads(t *testing.T) {
+	const RS, corpus = `\s*;\s*`, "a ; b ; c"
+	want, err := Split(nil, RS, FS, rdr(corpus))
+	failIf(t, 0, err)
+	var recs []*record
+	err = StreamSplit(nil, RS, FS, iotest.OneByteReader(rdr(corpus)), func(rec interface{}) error {
+		recs = append(recs, rec.(*record))
+		return nil
+	})
+	failIf(t, 0, err)
+	lines := make([]string, len(recs))
+	for i, r := range recs {
+		lines[i] = r.Line
+	}
+	failIf(t, 0, listEquals(0, []string{"a", "b", "c"}, lines))
+	for i, r := range want.([]*record) {
+		if recs[i].Start != r.Start || recs[i].End != r.End {
+			t.Errorf("record %d: expected offsets %d-%d, got %d-%d", i, r.Start, r.End, recs[i].Start, recs[i].End)
+		}
+	}
+}
+
+func TestStreamLineNumbers(t *testing.T) {
+	const corpus = "a\n\nb\n\n\nc"
+	var nrs []int
+	err := StreamSplit(nil, "\n", FS, iotest.OneByteReader(rdr(corpus)), func(rec interface{}) error {
+		nrs = append(nrs, rec.(*record).NR)
+		return nil
+	})
+	failIf(t, 0, err)
+	ret, err := Split(nil, "\n", FS, rdr(corpus))
+	failIf(t, 0, err)
+	recs := ret.([]*record)
+	for i, nr := range []int{1, 3, 6} {
+		if nrs[i] != nr || recs[i].NR != nr {
+			t.Errorf("record %d: expected NR %d, got %d streaming and %d not", i, nr, nrs[i], recs[i].NR)
+		}
+	}
+}
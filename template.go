@@ -9,6 +9,7 @@ import (
 
 type template interface {
 	New(string) template
+	Lookup(string) template
 	Parse(string) (template, error)
 	ExecuteTemplate(io.Writer, string, interface{}) error
 }
@@ -39,6 +40,13 @@ func (t *textTemplate) New(nm string) template {
 	return &textTemplate{t.t.New(nm)}
 }
 
+func (t *textTemplate) Lookup(nm string) template {
+	if x := t.t.Lookup(nm); x != nil {
+		return &textTemplate{x}
+	}
+	return nil
+}
+
 func (t *textTemplate) Parse(s string) (template, error) {
 	x, err := t.t.Parse(s)
 	if err != nil {
@@ -55,6 +63,13 @@ func (t *htmlTemplate) New(nm string) template {
 	return &htmlTemplate{t.t.New(nm)}
 }
 
+func (t *htmlTemplate) Lookup(nm string) template {
+	if x := t.t.Lookup(nm); x != nil {
+		return &htmlTemplate{x}
+	}
+	return nil
+}
+
 func (t *htmlTemplate) Parse(s string) (template, error) {
 	x, err := t.t.Parse(s)
 	if err != nil {
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var layouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+	//common and combined log formats, as used by Apache and nginx
+	"apache": "02/Jan/2006:15:04:05 -0700",
+	"clf":    "02/Jan/2006:15:04:05 -0700",
+}
+
+var strftime = map[byte]string{
+	'a': "Mon",
+	'A': "Monday",
+	'b': "Jan",
+	'B': "January",
+	'h': "Jan",
+	'd': "02",
+	'e': "_2",
+	'j': "002",
+	'm': "01",
+	'y': "06",
+	'Y': "2006",
+	'H': "15",
+	'I': "03",
+	'M': "04",
+	'S': "05",
+	'p': "PM",
+	'z': "-0700",
+	'Z': "MST",
+	'F': "2006-01-02",
+	'D': "01/02/06",
+	'T': "15:04:05",
+	'R': "15:04",
+	'n': "\n",
+	't': "\t",
+	'%': "%",
+}
+
+func layoutOf(layout string) (string, error) {
+	//a layout is the name of a layout, a strftime(3) format, or a Go layout
+	if l, ok := layouts[layout]; ok {
+		return l, nil
+	}
+	if !strings.Contains(layout, "%") {
+		return layout, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(layout); i++ {
+		if layout[i] != '%' {
+			b.WriteByte(layout[i])
+			continue
+		}
+		i++
+		if i == len(layout) {
+			return "", fmt.Errorf("layout %q ends with %%", layout)
+		}
+		l, ok := strftime[layout[i]]
+		if !ok {
+			return "", fmt.Errorf("unsupported conversion %%%c in layout %q", layout[i], layout)
+		}
+		b.WriteString(l)
+	}
+	return b.String(), nil
+}
+
+func toTime(v interface{}) (time.Time, error) {
+	//times, RFC 3339 strings, and seconds since the epoch are all times
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case string:
+		return time.Parse(time.RFC3339Nano, strings.TrimSpace(t))
+	case nil:
+		return time.Time{}, errors.New("missing value is not a time")
+	}
+	return fromUnix(v)
+}
+
+func toDuration(v interface{}) (time.Duration, error) {
+	//durations, strings like 1h30m, and seconds are all durations
+	switch d := v.(type) {
+	case time.Duration:
+		return d, nil
+	case string:
+		return time.ParseDuration(strings.TrimSpace(d))
+	}
+	return duration(v)
+}
+
+func parseTime(layout, s string) (time.Time, error) {
+	l, err := layoutOf(layout)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(l, strings.TrimSpace(s))
+}
+
+func formatTime(layout string, v interface{}) (string, error) {
+	l, err := layoutOf(layout)
+	if err != nil {
+		return "", err
+	}
+	t, err := toTime(v)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(l), nil
+}
+
+func unix(v interface{}) (int64, error) {
+	t, err := toTime(v)
+	if err != nil {
+		return 0, err
+	}
+	return t.Unix(), nil
+}
+
+func fromUnix(v interface{}) (time.Time, error) {
+	n, err := toNumber(v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if n.isInt {
+		return time.Unix(int64(n.i), 0), nil
+	}
+	d, err := duration(n.f)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, 0).Add(d), nil
+}
+
+func duration(v interface{}) (time.Duration, error) {
+	//seconds to a duration
+	n, err := toNumber(v)
+	if err != nil {
+		return 0, err
+	}
+	s := n.float() * float64(time.Second)
+	if s < -1<<63 || s >= 1<<63 {
+		return 0, fmt.Errorf("%v seconds is too long a duration", v)
+	}
+	return time.Duration(s), nil
+}
+
+func seconds(v interface{}) (float64, error) {
+	d, err := toDuration(v)
+	if err != nil {
+		return 0, err
+	}
+	return d.Seconds(), nil
+}
+
+func addTime(d, t interface{}) (time.Time, error) {
+	dur, err := toDuration(d)
+	if err != nil {
+		return time.Time{}, err
+	}
+	tm, err := toTime(t)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return tm.Add(dur), nil
+}
+
+func subTime(a, b interface{}) (time.Duration, error) {
+	x, err := toTime(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := toTime(b)
+	if err != nil {
+		return 0, err
+	}
+	return x.Sub(y), nil
+}
+
+func inZone(zone string, v interface{}) (time.Time, error) {
+	t, err := toTime(v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(loc), nil
+}
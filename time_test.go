@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLayoutOf(t *testing.T) {
+	var tests = []struct {
+		in, out string
+	}{
+		{"2006-01-02", "2006-01-02"},
+		{"apache", "02/Jan/2006:15:04:05 -0700"},
+		{"RFC3339", time.RFC3339},
+		{"%Y-%m-%dT%H:%M:%S%z", "2006-01-02T15:04:05-0700"},
+		{"%d/%b/%Y 100%%", "02/Jan/2006 100%"},
+	}
+	for i, v := range tests {
+		l, err := layoutOf(v.in)
+		failIf(t, i, err)
+		if l != v.out {
+			t.Errorf("test case %d: expected %q, got %q", i, v.out, l)
+		}
+	}
+	for _, bad := range []string{"%Q", "%"} {
+		if _, err := layoutOf(bad); err == nil {
+			t.Errorf("expected error for layout %q", bad)
+		}
+	}
+}
+
+func TestTimeFuncs(t *testing.T) {
+	tm, err := parseTime("apache", "10/Oct/2000:13:55:36 -0700")
+	failIf(t, 0, err)
+	s, err := formatTime("%Y-%m-%d %H:%M", tm)
+	failIf(t, 0, err)
+	if s != "2000-10-10 13:55" {
+		t.Errorf("expected 2000-10-10 13:55, got %s", s)
+	}
+
+	u, err := unix(tm)
+	failIf(t, 0, err)
+	if u != 971211336 {
+		t.Errorf("expected 971211336, got %d", u)
+	}
+	back, err := fromUnix(u)
+	failIf(t, 0, err)
+	if !back.Equal(tm) {
+		t.Errorf("expected %s, got %s", tm, back)
+	}
+
+	later, err := addTime("1h30m", tm)
+	failIf(t, 0, err)
+	d, err := subTime(later, "2000-10-10T20:55:36Z")
+	failIf(t, 0, err)
+	if d != 90*time.Minute {
+		t.Errorf("expected 1h30m, got %s", d)
+	}
+	secs, err := seconds(d)
+	failIf(t, 0, err)
+	if secs != 5400 {
+		t.Errorf("expected 5400 seconds, got %g", secs)
+	}
+
+	utc, err := inZone("UTC", tm)
+	failIf(t, 0, err)
+	if utc.Hour() != 20 {
+		t.Errorf("expected hour 20 in UTC, got %d", utc.Hour())
+	}
+}
+
+func TestTimeHeader(t *testing.T) {
+	ret, err := Split(splitHeader("when:time=%Y/%m/%d"), RS, FS, rdr("2001/02/03\n"))
+	failIf(t, 0, err)
+	when := ret.([]map[string]interface{})[0]["when"].(time.Time)
+	if when.Year() != 2001 || when.Month() != 2 || when.Day() != 3 {
+		t.Errorf("unexpected time %s", when)
+	}
+}
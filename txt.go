@@ -4,6 +4,7 @@ import (
 	"flag"
 	"log"
 	"os"
+	"path/filepath"
 )
 
 const (
@@ -23,12 +24,30 @@ var (
 	RecordSeparator = flag.String("R", RS, "record separator")
 	FieldSeparator  = flag.String("F", FS, "field separator")
 	LinePattern     = flag.String("L", "", "line pattern, regex must contain capture groups")
+	Widths          = flag.String("W", "", "fixed-width column ranges, such as 1-8,9-20")
+	Aligned         = flag.Bool("columns", false, "infer column positions from the header line")
 
 	Json    = flag.Bool("json", false, "treat input as JSON")
+	Jsonl   = flag.Bool("jsonl", false, "treat input as a stream of JSON values")
 	Csv     = flag.Bool("csv", false, "treat input as CSV")
+	Yaml    = flag.Bool("yaml", false, "treat input as YAML")
+	Toml    = flag.Bool("toml", false, "treat input as TOML")
+	Xml     = flag.Bool("xml", false, "treat input as XML")
 	NoStdin = flag.Bool("no-stdin", false, "do not read stdin")
 
-	Header = flag.String("header", "", "specify a header as a comma-separated list")
+	Streaming = flag.Bool("stream", false, "execute template once per record")
+
+	Vars     = varsFlag("var", "define a variable as name=value, may be repeated")
+	VarsFile = flag.String("vars-file", "", "read variables from a JSON or name=value file")
+
+	Safe    = flag.Bool("safe", false, "disable functions that run commands or read files")
+	Allow   = flag.String("allow", "", "comma-separated list of functions to enable with -safe")
+	Strict  = flag.Bool("strict", false, "halt if a command fails")
+	Timeout = flag.Duration("timeout", 0, "kill commands that run longer than this")
+
+	Header     = flag.String("header", "", "specify a header as a comma-separated list")
+	HeaderLine = flag.Bool("header-line", false, "use the first record as the header")
+	Ordered    = flag.Bool("ordered", false, "keep the order of the header in each row")
 )
 
 //Usage: %name %flags template-files*
@@ -36,10 +55,12 @@ func main() {
 	log.SetFlags(0)
 
 	flag.Usage = func() {
-		log.Printf("Usage: %s [-json|-csv|-no-stdin] -html -left=delim -right=delim\n", os.Args[0])
+		log.Printf("Usage: %s [-json|-jsonl|-csv|-yaml|-toml|-xml|-no-stdin] -html -left=delim -right=delim\n", os.Args[0])
 		p := log.Println
-		p("\t[-e=template|-template=name] -R=RE [-F=RE|-L=RE]")
-		p("\t-header=headerspec template-files*")
+		p("\t[-e=template|-template=name] -stream -R=RE [-F=RE|-L=RE|-W=ranges|-columns]")
+		p("\t[-header=headerspec|-header-line] -ordered -safe -allow=functions")
+		p("\t-strict -timeout=duration [-var=name=value]* -vars-file=file template-files*")
+		p("\t[-- args*]")
 
 		p(" Template control:")
 		p("  -left delim:    set the left delimiter in templates")
@@ -48,19 +69,39 @@ func main() {
 		p(" Template selection:")
 		p("  -e template:    specifiy main template as string")
 		p("  -template file: say which of the template files is the main template")
+		p(" Variables:")
+		p("  -var name=value: define the variable name, may be repeated")
+		p("  -vars-file file: define the variables in a JSON object or name=value lines")
+		p(" Commands:")
+		p("  -safe:          disable exec, pipe, sh, read, env, and parse functions")
+		p("  -allow list:    comma-separated functions to enable anyway, implies -safe")
+		p("  -strict:        halt if a command run by exec, pipe, or sh fails")
+		p("  -timeout dur:   kill commands that run longer than dur, such as 10s")
 		p(" Input handling")
 		p("  -json:          parse input as JSON")
+		p("  -jsonl:         parse input as JSON Lines")
 		p("  -csv:           parse input as CSV")
+		p("  -yaml:          parse input as YAML")
+		p("  -toml:          parse input as TOML")
+		p("  -xml:           parse input as XML")
 		p("  -no-stdin:      do not read stdin")
+		p("  -stream:        execute the template once per record as read")
 		p("  -R regex:       record separator, defaults to \"\\n+\"")
 		p("  -F regex:       field separator, defaults to \"\\s+\"")
 		p("  -L regex:       line-matching pattern")
+		p("  -W ranges:      comma-separated list of fixed-width column ranges")
+		p("  -columns:       infer column positions from the header line")
 		p("  -header list:   comma-separated list of field names")
+		p("  -header-line:   use the first record as the header, same as -header=-")
+		p("  -ordered:       rows with a header keep the order of the header")
 
 		p("-e and -template are mutually exclusive")
-		p("Only one of -json, -csv, -no-stdin, -F, or -L can be specified")
-		p("-header can only be used with -csv, -F, or -L")
-		p("-R can only be used with -F or -L")
+		p("Only one of -json, -jsonl, -csv, -yaml, -toml, -xml, -no-stdin, -F, -L,")
+		p("\t-W, or -columns can be specified")
+		p("-header can only be used with -csv, -F, -L, -W, or -columns")
+		p("-header-line can only be used with -F, -L, or -W, and not with -header")
+		p("-R can only be used with -F, -L, -W, or -columns")
+		p("-stream can only be used with -F or -L")
 
 		os.Exit(2)
 	}
@@ -71,10 +112,10 @@ func main() {
 	if *Expression != "" && *Template != "" {
 		fail = true
 	}
-	if multiple(*Csv, *Json, *NoStdin) {
+	if multiple(*Csv, *Json, *Jsonl, *Yaml, *Toml, *Xml, *NoStdin) {
 		fail = true
 	}
-	notregex := oneOf(*Csv, *Json, *NoStdin)
+	notregex := oneOf(*Csv, *Json, *Jsonl, *Yaml, *Toml, *Xml, *NoStdin)
 	if notregex && *RecordSeparator != RS {
 		fail = true
 	}
@@ -84,18 +125,32 @@ func main() {
 	if notregex && *LinePattern != "" {
 		fail = true
 	}
-	if (*Json || *NoStdin) && *Header != "" {
+	if (*Json || *Jsonl || *Yaml || *Toml || *Xml || *NoStdin) && *Header != "" {
 		fail = true
 	}
 	if *FieldSeparator != FS && *LinePattern != "" {
 		fail = true
 	}
+	columns := *Widths != "" || *Aligned
+	if *Widths != "" && *Aligned {
+		fail = true
+	}
+	if columns && (notregex || *FieldSeparator != FS || *LinePattern != "") {
+		fail = true
+	}
+	if (notregex || columns) && *Streaming {
+		fail = true
+	}
+	if *HeaderLine && (notregex || *Aligned || *Header != "") {
+		fail = true
+	}
 	if fail {
 		log.Println("Invalid combination of flags")
 		flag.Usage()
 		os.Exit(2)
 	}
-	args := flag.Args()
+	//flag.Parse consumes a -- that ends the flags, so look for it in os.Args
+	args, params := splitArgs(flag.Args(), flagsEnded(os.Args[1:]))
 
 	var which string
 
@@ -105,19 +160,60 @@ func main() {
 	//If template(s) specified use first as main unless specified by flag.
 	//otherwise no template
 	if len(args) > 0 {
-		which = args[0]
+		which = filepath.Base(args[0]) //templates are named by basename
 		if *Template != "" {
 			which = *Template
 		}
 	} else if *Expression == "" {
 		log.Fatalln("No template(s) specified")
 	}
-	tmpl, err := Parse(*Html, *Expression, *Left, *Right, funcs, args...)
+	fs := funcs
+	if *Safe || *Allow != "" {
+		var err error
+		if fs, err = restrict(funcs, splitHeader(*Allow)); err != nil {
+			log.Fatalln(err)
+		}
+	}
+	vars, err := loadVars(*VarsFile, Vars)
 	if err != nil {
 		log.Fatalln(err)
 	}
+	fs = bind(fs, varFuncs(vars), map[string]interface{}{
+		"args": func() []string {
+			return params
+		},
+	})
+	tmpl, err := Parse(*Html, *Expression, *Left, *Right, fs, args...)
+	if err != nil {
+		log.Fatalln(disallowed(err))
+	}
 
 	hdr := splitHeader(*Header)
+	if *HeaderLine {
+		hdr = []string{FirstLine}
+	}
+
+	//execute once per record, bracketed by BEGIN and END, if defined
+	if *Streaming {
+		if err = executeIfDefined(tmpl, "BEGIN"); err != nil {
+			log.Fatalln(err)
+		}
+		each := func(rec interface{}) error {
+			return tmpl.ExecuteTemplate(os.Stdout, which, rec)
+		}
+		if *LinePattern != "" {
+			err = StreamSubmatchSplit(hdr, *RecordSeparator, *LinePattern, os.Stdin, each)
+		} else {
+			err = StreamSplit(hdr, *RecordSeparator, *FieldSeparator, os.Stdin, each)
+		}
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if err = executeIfDefined(tmpl, "END"); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
 
 	//parse input
 	var stdin interface{}
@@ -125,6 +221,16 @@ func main() {
 		stdin, err = CSV(hdr, os.Stdin)
 	} else if *Json {
 		stdin, err = JSON(os.Stdin)
+	} else if *Jsonl {
+		stdin, err = JSONL(os.Stdin)
+	} else if *Yaml {
+		stdin, err = YAML(os.Stdin)
+	} else if *Toml {
+		stdin, err = TOML(os.Stdin)
+	} else if *Xml {
+		stdin, err = XML(os.Stdin)
+	} else if columns {
+		stdin, err = Columns(hdr, *RecordSeparator, *Widths, os.Stdin)
 	} else if *LinePattern != "" {
 		stdin, err = SubmatchSplit(hdr, *RecordSeparator, *LinePattern, os.Stdin)
 	} else if !*NoStdin {
@@ -139,3 +245,10 @@ func main() {
 		log.Fatalln(err)
 	}
 }
+
+func executeIfDefined(t template, name string) error {
+	if t.Lookup(name) == nil {
+		return nil
+	}
+	return t.ExecuteTemplate(os.Stdout, name, nil)
+}
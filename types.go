@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type conversion func(string) (interface{}, error)
+
+func convert(typ string) (conversion, error) {
+	name, layout := typ, time.RFC3339
+	if i := strings.Index(typ, "="); i >= 0 {
+		name, layout = typ[:i], typ[i+1:]
+	}
+
+	var (
+		zero interface{}
+		c    conversion
+	)
+	switch name {
+	case "string":
+		zero, c = "", func(s string) (interface{}, error) {
+			return s, nil
+		}
+	case "int":
+		zero, c = 0, func(s string) (interface{}, error) {
+			return strconv.Atoi(s)
+		}
+	case "float":
+		zero, c = 0.0, func(s string) (interface{}, error) {
+			return strconv.ParseFloat(s, 64)
+		}
+	case "bool":
+		zero, c = false, func(s string) (interface{}, error) {
+			return strconv.ParseBool(s)
+		}
+	case "time":
+		l, err := layoutOf(layout)
+		if err != nil {
+			return nil, err
+		}
+		zero, c = time.Time{}, func(s string) (interface{}, error) {
+			return time.Parse(l, s)
+		}
+	default:
+		return nil, fmt.Errorf("unknown type %q in header", typ)
+	}
+
+	return func(s string) (interface{}, error) {
+		//empty fields are usually missing, not malformed
+		if s == "" {
+			return zero, nil
+		}
+		v, err := c(s)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %q to %s", s, typ)
+		}
+		return v, nil
+	}, nil
+}
+
+func headerTypes(header []string) (names []string, types map[string]conversion, err error) {
+	if isFirstLine(header) {
+		return header, nil, nil
+	}
+	for _, h := range header {
+		name := h
+		if i := strings.Index(h, ":"); i >= 0 {
+			name = strings.TrimSpace(h[:i])
+			if types == nil {
+				types = map[string]conversion{}
+			}
+			if types[name], err = convert(strings.TrimSpace(h[i+1:])); err != nil {
+				return nil, nil, err
+			}
+		}
+		names = append(names, name)
+	}
+	return names, types, nil
+}
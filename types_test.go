@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTypedHeader(t *testing.T) {
+	hdr := splitHeader("name, size:int, ratio:float, ok:bool, when:time=2006-01-02")
+	ret, err := Split(hdr, RS, FS, rdr("a 10 0.5 true 2014-02-03\nb\n"))
+	failIf(t, 0, err)
+	rows := ret.([]map[string]interface{})
+	if len(rows) != 2 {
+		t.Fatalf("wrong number of rows: %d ≠ 2", len(rows))
+	}
+	want := map[string]interface{}{
+		"name":  "a",
+		"size":  10,
+		"ratio": 0.5,
+		"ok":    true,
+		"when":  time.Date(2014, 2, 3, 0, 0, 0, 0, time.UTC),
+	}
+	for k, v := range want {
+		if rows[0][k] != v {
+			t.Errorf("%s: %#v ≠ %#v", k, rows[0][k], v)
+		}
+	}
+	if rows[1]["size"] != 0 {
+		t.Errorf("empty field not converted to zero value: %#v", rows[1]["size"])
+	}
+}
+
+func TestTypedHeaderErrors(t *testing.T) {
+	_, err := CSV(splitHeader("a,b:int"), rdr("x,1\ny,z\n"))
+	if err == nil || err.Error() != `1: column "b": cannot convert "z" to int` {
+		t.Errorf("wrong error returned: %v", err)
+	}
+
+	//records that are skipped still count
+	_, err = SubmatchSplit(splitHeader("a,b:int"), RS, `(\w+) (\S+)`, rdr("#\nx 1\ny z\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "2: ") {
+		t.Errorf("wrong error returned: %v", err)
+	}
+	_, err = Split(splitHeader("a,b:int"), "\n", FS, rdr("x 1\n\ny z\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "2: ") {
+		t.Errorf("wrong error returned: %v", err)
+	}
+
+	_, err = Split(splitHeader("a:complex"), RS, FS, rdr(""))
+	if err == nil || !strings.Contains(err.Error(), "unknown type") {
+		t.Errorf("wrong error returned: %v", err)
+	}
+}
@@ -2,18 +2,52 @@ package main
 
 import (
 	"bytes"
+	"flag"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"reflect"
 	"regexp"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 func rdr(corpus string) io.Reader {
 	return strings.NewReader(corpus)
 }
 
+type namedReader struct {
+	io.Reader
+	name string
+}
+
+func (r namedReader) Name() string {
+	return r.name
+}
+
+func named(input string, name []string) (io.Reader, error) {
+	//input as a reader with the optional name for FILENAME
+	switch len(name) {
+	case 0:
+		return rdr(input), nil
+	case 1:
+		return namedReader{rdr(input), name[0]}, nil
+	}
+	return nil, fmt.Errorf("expected at most one name, given %d", len(name))
+}
+
+func filename(r io.Reader) string {
+	if r == os.Stdin {
+		return "stdin"
+	}
+	if f, ok := r.(interface{ Name() string }); ok {
+		return f.Name()
+	}
+	return ""
+}
+
 var intSize = reflect.ValueOf(0)
 
 func overflow(i int64) (int, error) {
@@ -54,11 +88,70 @@ func cmpl(p string) (*regexp.Regexp, error) {
 	return r, nil
 }
 
-func run(c *exec.Cmd) string {
-	var out bytes.Buffer
+type result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Duration time.Duration
+}
+
+func (r *result) String() string {
+	return r.Stdout
+}
+
+func run(c *exec.Cmd, stderr io.Writer) (*result, error) {
+	//the returned result is nil only if c could not be started.
+	//stderr, if not nil, receives the command's stderr as it is written.
+	var out, errs bytes.Buffer
 	c.Stdout = &out
-	_ = c.Run()
-	return out.String()
+	c.Stderr = &errs
+	if stderr != nil {
+		c.Stderr = io.MultiWriter(&errs, stderr)
+	}
+	//do not wait forever on pipes held open by the children of a killed command
+	c.WaitDelay = time.Second
+
+	start := time.Now()
+	if err := c.Start(); err != nil {
+		return nil, err
+	}
+	var killed atomic.Bool
+	if *Timeout > 0 {
+		timer := time.AfterFunc(*Timeout, func() {
+			killed.Store(true)
+			_ = c.Process.Kill()
+		})
+		defer timer.Stop()
+	}
+	err := c.Wait()
+	//the timer may fire as a command exits, so it only timed out if
+	//it was killed instead of exiting on its own
+	if killed.Load() && !c.ProcessState.Exited() {
+		err = fmt.Errorf("timed out after %s", *Timeout)
+	}
+
+	return &result{
+		Stdout:   out.String(),
+		Stderr:   errs.String(),
+		ExitCode: c.ProcessState.ExitCode(),
+		Duration: time.Since(start),
+	}, err
+}
+
+func output(c *exec.Cmd) (string, error) {
+	//stdout of c or, with -strict, an error if c fails
+	r, err := run(c, os.Stderr)
+	if err != nil && *Strict {
+		msg := err.Error()
+		if r != nil && strings.TrimSpace(r.Stderr) != "" {
+			msg += ": " + strings.TrimSpace(r.Stderr)
+		}
+		return "", fmt.Errorf("%s: %s", c.Args[0], msg)
+	}
+	if r == nil {
+		return "", nil
+	}
+	return r.Stdout, nil
 }
 
 func hdr2map(h []string, submatch bool) (out map[string]int) {
@@ -78,6 +171,12 @@ func hdr2map(h []string, submatch bool) (out map[string]int) {
 	return
 }
 
+const FirstLine = "-" //as the only name in a header, use the first record as the header
+
+func isFirstLine(h []string) bool {
+	return len(h) == 1 && h[0] == FirstLine
+}
+
 func splitHeader(h string) (out []string) {
 	if h == "" {
 		return
@@ -88,6 +187,44 @@ func splitHeader(h string) (out []string) {
 	return
 }
 
+func flagsEnded(args []string) bool {
+	//whether flag.Parse stopped at a -- in args, rather than at an argument,
+	//skipping the values of flags that are not booleans as it does
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			return true
+		}
+		if len(a) < 2 || a[0] != '-' {
+			return false
+		}
+		name := strings.TrimLeft(a, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		if f := flag.Lookup(name); f != nil {
+			if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !b.IsBoolFlag() {
+				i++
+			}
+		}
+	}
+	return false
+}
+
+func splitArgs(args []string, ended bool) (files, params []string) {
+	//the template files before --, and the arguments after it.
+	//ended is whether the flags were ended by a -- already removed from args
+	if ended {
+		return nil, args
+	}
+	for i, a := range args {
+		if a == "--" {
+			return args[:i], args[i+1:]
+		}
+	}
+	return args, nil
+}
+
 func oneOf(these ...bool) bool {
 	for _, p := range these {
 		if p {
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	r, err := run(exec.Command("sh", "-c", "echo out; echo err >&2; exit 3"), nil)
+	if err == nil {
+		t.Error("expected error for non-zero exit")
+	}
+	if r.Stdout != "out\n" || r.Stderr != "err\n" || r.ExitCode != 3 {
+		t.Errorf("unexpected result %+v", r)
+	}
+
+	if _, err := run(exec.Command("/nonexistent/command"), nil); err == nil {
+		t.Error("expected error for missing command")
+	}
+
+	*Timeout = 50 * time.Millisecond
+	defer func() { *Timeout = 0 }()
+	r, err = run(exec.Command("sleep", "5"), nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout, got %v", err)
+	}
+	if r.Duration > 2*time.Second {
+		t.Errorf("command ran for %s after timeout", r.Duration)
+	}
+
+	//commands that exit on their own never time out, even if the timer fires
+	for i := 0; i < 20; i++ {
+		*Timeout = time.Duration(i) * time.Millisecond / 2
+		if r, err := run(exec.Command("true"), nil); err != nil && r.ExitCode != -1 {
+			t.Errorf("exit %d reported as %s", r.ExitCode, err)
+		}
+	}
+}
+
+func TestOutputStrict(t *testing.T) {
+	c := func() *exec.Cmd {
+		return exec.Command("sh", "-c", "echo partial; echo broken >&2; exit 1")
+	}
+	out, err := output(c())
+	failIf(t, 0, err)
+	if out != "partial\n" {
+		t.Errorf("expected partial output, got %q", out)
+	}
+
+	*Strict = true
+	defer func() { *Strict = false }()
+	if _, err := output(c()); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected error with stderr, got %v", err)
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	var tests = []struct {
+		in            []string
+		ended         bool
+		files, params []string
+	}{
+		{[]string{"a", "b"}, false, []string{"a", "b"}, nil},
+		{[]string{"a", "--", "b", "--"}, false, []string{"a"}, []string{"b", "--"}},
+		{[]string{"a", "b"}, true, nil, []string{"a", "b"}},
+		{[]string{"--"}, false, []string{}, []string{}},
+	}
+	for i, v := range tests {
+		files, params := splitArgs(v.in, v.ended)
+		failIf(t, i, listEquals(i, v.files, files))
+		failIf(t, i, listEquals(i, v.params, params))
+	}
+}
+
+func TestFlagsEnded(t *testing.T) {
+	var tests = []struct {
+		args  []string
+		ended bool
+	}{
+		{[]string{"-no-stdin", "--", "a"}, true},
+		{[]string{"-no-stdin", "-e", "--", "a", "b"}, false},
+		{[]string{"-e=--", "a"}, false},
+		{[]string{"-e", "x", "--", "a"}, true},
+		{[]string{"a", "--", "b"}, false},
+		{[]string{"-no-stdin=true", "--"}, true},
+	}
+	for i, v := range tests {
+		if ended := flagsEnded(v.args); ended != v.ended {
+			t.Errorf("test case %d: expected %v for %q", i, v.ended, v.args)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type variables map[string]string
+
+func varsFlag(name, usage string) variables {
+	v := variables{}
+	flag.Var(v, name, usage)
+	return v
+}
+
+func (v variables) String() string {
+	out := make([]string, 0, len(v))
+	for name, value := range v {
+		out = append(out, name+"="+value)
+	}
+	sort.Strings(out)
+	return strings.Join(out, ",")
+}
+
+func (v variables) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return fmt.Errorf("variable %q is not of the form name=value", s)
+	}
+	v[s[:i]] = s[i+1:]
+	return nil
+}
+
+func envVars(r io.Reader) (map[string]interface{}, error) {
+	//lines of name=value, as in .env files and the output of env(1)
+	vars := map[string]interface{}{}
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		i := strings.Index(line, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("line %d: %q is not of the form name=value", n, line)
+		}
+		name, value := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		if len(value) > 1 && value[0] == '"' && value[len(value)-1] == '"' {
+			var err error
+			if value, err = strconv.Unquote(value); err != nil {
+				return nil, fmt.Errorf("line %d: %s", n, err)
+			}
+		} else if len(value) > 1 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = value[1 : len(value)-1]
+		}
+		vars[name] = value
+	}
+	return vars, sc.Err()
+}
+
+func readVars(file string) (map[string]interface{}, error) {
+	//a JSON object or, otherwise, lines of name=value
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		var vars map[string]interface{}
+		if err := json.Unmarshal(b, &vars); err != nil {
+			return nil, fmt.Errorf("%s: %s", file, err)
+		}
+		return vars, nil
+	}
+	vars, err := envVars(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+	return vars, nil
+}
+
+func loadVars(file string, vs variables) (map[string]interface{}, error) {
+	//the variables of file, if any, overridden by vs
+	vars := map[string]interface{}{}
+	if file != "" {
+		var err error
+		if vars, err = readVars(file); err != nil {
+			return nil, err
+		}
+	}
+	for name, value := range vs {
+		vars[name] = value
+	}
+	return vars, nil
+}
+
+func varFuncs(vars map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"var": func(name string) (interface{}, error) {
+			v, ok := vars[name]
+			if !ok {
+				return nil, fmt.Errorf("variable %q is not defined", name)
+			}
+			return v, nil
+		},
+		"vars": func() map[string]interface{} {
+			return vars
+		},
+	}
+}
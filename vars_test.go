@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvVars(t *testing.T) {
+	vars, err := envVars(rdr("# comment\n\nexport A=1\nB = \"two\\tthree\"\nC='four'\nD=a=b\n"))
+	failIf(t, 0, err)
+	expected := map[string]string{"A": "1", "B": "two\tthree", "C": "four", "D": "a=b"}
+	if len(vars) != len(expected) {
+		t.Errorf("expected %d variables, got %v", len(expected), vars)
+	}
+	for name, value := range expected {
+		if vars[name] != value {
+			t.Errorf("expected %s=%q, got %q", name, value, vars[name])
+		}
+	}
+
+	if _, err := envVars(rdr("novalue\n")); err == nil {
+		t.Error("expected error for line without =")
+	}
+}
+
+func TestLoadVars(t *testing.T) {
+	dir, err := ioutil.TempDir("", "txt")
+	failIf(t, 0, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "vars.json")
+	failIf(t, 0, ioutil.WriteFile(file, []byte(`{"n": 1, "s": "file"}`), 0600))
+
+	vs := variables{}
+	failIf(t, 0, vs.Set("s=flag"))
+	if err := vs.Set("=x"); err == nil {
+		t.Error("expected error for empty name")
+	}
+	vars, err := loadVars(file, vs)
+	failIf(t, 0, err)
+
+	tmpl, err := Parse(false, `{{var "n"}} {{var "s"}} {{len vars}}`, "{{", "}}", bind(funcs, varFuncs(vars)))
+	failIf(t, 0, err)
+	var b bytes.Buffer
+	failIf(t, 0, tmpl.ExecuteTemplate(&b, "", nil))
+	if out := b.String(); out != "1 flag 2" {
+		t.Errorf("expected %q, got %q", "1 flag 2", out)
+	}
+}